@@ -1,6 +1,7 @@
 module github.com/EnvCLI/EnvCLI
 
-go 1.19
+// golang.org/x/crypto v0.35.0 (openpgp of go-github) requires go 1.23.0
+go 1.23.0
 
 require (
 	github.com/blang/semver v3.5.1+incompatible
@@ -8,10 +9,12 @@ require (
 	github.com/google/go-github/v26 v26.1.3
 	github.com/inconshreveable/go-update v0.0.0-20160112193335-8152e7eb6ccf
 	github.com/jinzhu/configor v1.2.1
+	github.com/klauspost/compress v1.16.0
 	github.com/mattn/go-colorable v0.1.13
 	github.com/rs/zerolog v1.29.0
 	github.com/spf13/cobra v1.6.1
 	github.com/thoas/go-funk v0.9.3
+	golang.org/x/sys v0.30.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -24,6 +27,5 @@ require (
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.35.0 // indirect
-	golang.org/x/sys v0.30.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 )
@@ -1,42 +1,159 @@
 package aliases
 
 import (
-	"github.com/cidverse/cidverseutils/pkg/filesystem"
-	"github.com/rs/zerolog/log"
-	"io/ioutil"
+	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 
-	common "github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/rs/zerolog/log"
 )
 
-// InstallAlias installs simple aliases that pass all parameters to envcli run
-func InstallAlias(command string, scope string) error {
-	log.Debug().Str("command", command).Str("scope", scope).Msg("Installing alias ...")
+// GetAliasPath returns the directory the aliases are installed into, defaults to ~/.local/share/envcli/bin
+func GetAliasPath(properties map[string]string) string {
+	aliasPath := strings.TrimSpace(properties["alias-path"])
+	if aliasPath == "" {
+		aliasPath = filepath.Join(config.GetDataDirectory(), "bin")
+	}
+
+	return aliasPath
+}
 
-	// download alias script for each used command
-	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
-		log.Debug().Msg("Detected Linux - Will place bash scripts into PATH ...")
+// ModeScript installs a small script that calls envcli run
+const ModeScript = "script"
 
-		scriptData, err := Asset("scripts/alias.sh")
-		common.CheckForError(err)
+// ModeSymlink installs a symlink to the envcli binary, envcli detects the command from the name it was invoked with
+const ModeSymlink = "symlink"
 
-		err = ioutil.WriteFile(filesystem.GetExecutionDirectory()+"/"+command, scriptData, 0755)
-		common.CheckForError(err)
+// ModeHardlink installs a hardlink to the envcli binary, for filesystems or platforms without symlink support
+const ModeHardlink = "hardlink"
 
-		log.Debug().Str("command", command).Msg("Installed alias!")
-	} else if runtime.GOOS == "windows" {
-		log.Debug().Msg("Detected Windows - Will place cmd scripts into PATH ...")
+// InstallAlias installs a alias for the command into the alias directory, that passes all parameters to envcli run
+func InstallAlias(aliasPath string, command string, scope string, mode string) error {
+	log.Debug().Str("command", command).Str("scope", scope).Str("mode", mode).Str("dir", aliasPath).Msg("Installing alias ...")
+	if command == "" || strings.ContainsAny(command, `/\`) {
+		return errors.New("invalid command name [" + command + "]")
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return errors.New("aliases are not supported on " + runtime.GOOS)
+	}
+	if err := os.MkdirAll(aliasPath, 0755); err != nil {
+		return err
+	}
 
-		scriptData, err := Asset("scripts/alias.cmd")
-		common.CheckForError(err)
+	aliasFile := AliasFile(aliasPath, command, mode)
+	if err := os.Remove(aliasFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 
-		err = ioutil.WriteFile(filesystem.GetExecutionDirectory()+"/"+command+".cmd", scriptData, 0755)
-		common.CheckForError(err)
+	switch mode {
+	case ModeScript:
+		script := "scripts/alias.sh"
+		if runtime.GOOS == "windows" {
+			script = "scripts/alias.cmd"
+		}
+		scriptData, err := Asset(script)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(aliasFile, scriptData, 0755); err != nil {
+			return err
+		}
+	case ModeSymlink, ModeHardlink:
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return err
+		}
 
-		log.Debug().Str("command", command).Msg("Installed alias!")
-	} else {
-		log.Error().Str("command", command).Str("platform",  runtime.GOOS).Msg("Failed to install alias! Not supported on current platform.")
+		if mode == ModeSymlink {
+			err = os.Symlink(executable, aliasFile)
+		} else {
+			err = os.Link(executable, aliasFile)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid alias mode [" + mode + "], allowed: script, symlink, hardlink")
 	}
 
+	log.Debug().Str("command", command).Msg("Installed alias!")
 	return nil
 }
+
+// LinkedCommand returns the command if envcli was invoked through a symlink or hardlink alias of the manifest
+func LinkedCommand(manifest Manifest, invokedAs string) (string, bool) {
+	command := filepath.Base(invokedAs)
+	if runtime.GOOS == "windows" {
+		command = strings.TrimSuffix(command, filepath.Ext(command))
+	}
+
+	alias, ok := manifest.Get(command)
+	if !ok || (alias.Mode != ModeSymlink && alias.Mode != ModeHardlink) {
+		return "", false
+	}
+
+	return command, true
+}
+
+// FindHostBinary looks up the command on PATH, ignoring the alias directory, and returns the path of the host binary
+func FindHostBinary(command string, aliasPath string) (string, bool) {
+	extensions := []string{""}
+	if runtime.GOOS == "windows" {
+		extensions = strings.Split(strings.ToLower(os.Getenv("PATHEXT")), ";")
+	}
+
+	for _, pathEntry := range filepath.SplitList(os.Getenv("PATH")) {
+		if pathEntry == "" || filepath.Clean(pathEntry) == filepath.Clean(aliasPath) {
+			continue
+		}
+
+		for _, extension := range extensions {
+			candidate := filepath.Join(pathEntry, command+extension)
+			info, err := os.Stat(candidate)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+				continue
+			}
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// IsOnPath checks if the directory is part of the PATH environment variable
+func IsOnPath(dir string) bool {
+	for _, pathEntry := range filepath.SplitList(os.Getenv("PATH")) {
+		if pathEntry != "" && filepath.Clean(pathEntry) == filepath.Clean(dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PathHint returns the shell rc file and the line that adds the directory to the PATH of the current shell, on windows a powershell command that adds it to the user PATH
+func PathHint(dir string) (string, string) {
+	if runtime.GOOS == "windows" {
+		// setx would copy the machine PATH into the user PATH and truncate it at 1024 characters
+		quotedDir := strings.ReplaceAll(dir, "'", "''")
+		return "", `[Environment]::SetEnvironmentVariable('Path', [Environment]::GetEnvironmentVariable('Path', 'User') + ';` + quotedDir + `', 'User')`
+	}
+
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		return "~/.zshrc", `export PATH="` + dir + `:$PATH"`
+	case "fish":
+		return "~/.config/fish/config.fish", `fish_add_path "` + dir + `"`
+	}
+
+	return "~/.bashrc", `export PATH="` + dir + `:$PATH"`
+}
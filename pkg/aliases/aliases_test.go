@@ -0,0 +1,87 @@
+package aliases
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestGetAliasPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/xdg-data")
+
+	if aliasPath := GetAliasPath(map[string]string{}); aliasPath != filepath.Join("/tmp/xdg-data", "envcli", "bin") {
+		t.Errorf("expected the default alias path below XDG_DATA_HOME, got %s", aliasPath)
+	}
+	if aliasPath := GetAliasPath(map[string]string{"alias-path": "/opt/envcli/bin"}); aliasPath != "/opt/envcli/bin" {
+		t.Errorf("expected the alias-path property to be used, got %s", aliasPath)
+	}
+}
+
+func TestIsOnPath(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin"+string(filepath.ListSeparator)+"/home/user/.local/share/envcli/bin/")
+
+	if !IsOnPath("/home/user/.local/share/envcli/bin") {
+		t.Error("expected the directory to be detected on PATH")
+	}
+	if IsOnPath("/opt/envcli/bin") {
+		t.Error("expected the directory not to be on PATH")
+	}
+}
+
+func TestManifestReplacesAliasesByCommand(t *testing.T) {
+	aliasPath := t.TempDir()
+	manifest := Manifest{}
+	manifest.Add(Alias{Command: "go", Scope: "Global", Version: "v1"})
+	manifest.Add(Alias{Command: "npm", Scope: "Global", Version: "v1"})
+	manifest.Add(Alias{Command: "go", Scope: "Project", Project: "/src/a", Version: "v2"})
+	manifest.Remove("npm")
+
+	if err := SaveManifest(aliasPath, manifest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadManifest(aliasPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	alias, ok := loaded.Get("go")
+	if len(loaded.Aliases) != 1 || !ok || alias.Scope != "Project" || alias.Project != "/src/a" {
+		t.Errorf("expected only the project alias for go, got %+v", loaded.Aliases)
+	}
+}
+
+func TestLinkedCommand(t *testing.T) {
+	manifest := Manifest{}
+	manifest.Add(Alias{Command: "go", Mode: ModeSymlink})
+	manifest.Add(Alias{Command: "npm", Mode: ModeScript})
+
+	if command, ok := LinkedCommand(manifest, "/home/user/.local/share/envcli/bin/go"); !ok || command != "go" {
+		t.Errorf("expected the symlink alias to be detected, got %s", command)
+	}
+	if _, ok := LinkedCommand(manifest, "npm"); ok {
+		t.Error("expected script aliases not to be dispatched")
+	}
+	if _, ok := LinkedCommand(manifest, "/usr/local/bin/envcli"); ok {
+		t.Error("expected envcli itself not to be dispatched")
+	}
+}
+
+func TestFindHostBinaryIgnoresAliasPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses unix executable permissions")
+	}
+	aliasPath := t.TempDir()
+	hostPath := t.TempDir()
+	_ = os.WriteFile(filepath.Join(aliasPath, "git"), []byte("#!/bin/sh"), 0755)
+	t.Setenv("PATH", aliasPath+string(filepath.ListSeparator)+hostPath)
+
+	if _, found := FindHostBinary("git", aliasPath); found {
+		t.Error("expected the alias itself not to be reported as host binary")
+	}
+
+	_ = os.WriteFile(filepath.Join(hostPath, "git"), []byte("#!/bin/sh"), 0755)
+	if hostBinary, found := FindHostBinary("git", aliasPath); !found || hostBinary != filepath.Join(hostPath, "git") {
+		t.Errorf("expected the host binary to be found, got %s", hostBinary)
+	}
+}
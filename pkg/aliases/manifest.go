@@ -0,0 +1,116 @@
+package aliases
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// manifestFileName is the name of the file that tracks the installed aliases, stored in the alias directory
+const manifestFileName = ".envcli-aliases.yml"
+
+// Manifest tracks the aliases installed by envcli
+type Manifest struct {
+	Aliases []Alias `yaml:"aliases"`
+}
+
+// Alias is a single installed alias
+type Alias struct {
+	Command string `yaml:"command"`
+	Scope   string `yaml:"scope"`
+	// Project is the project directory of project scoped aliases
+	Project string `yaml:"project,omitempty"`
+	// Mode is the kind of alias, script, symlink or hardlink
+	Mode string `yaml:"mode"`
+	// Version of envcli that installed the alias
+	Version string `yaml:"version"`
+	// Path of the alias file
+	Path string `yaml:"path"`
+	// Force installs the alias even if it shadows a host binary
+	Force bool `yaml:"-"`
+}
+
+// LoadManifest loads the alias manifest, a missing manifest results in a empty manifest
+func LoadManifest(aliasPath string) (Manifest, error) {
+	var manifest Manifest
+
+	content, err := os.ReadFile(filepath.Join(aliasPath, manifestFileName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	} else if err != nil {
+		return manifest, err
+	}
+
+	err = yaml.Unmarshal(content, &manifest)
+	return manifest, err
+}
+
+// SaveManifest saves the alias manifest sorted by command
+func SaveManifest(aliasPath string, manifest Manifest) error {
+	sort.Slice(manifest.Aliases, func(i, j int) bool {
+		return manifest.Aliases[i].Command < manifest.Aliases[j].Command
+	})
+	content, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(aliasPath, 0755); err != nil {
+		return err
+	}
+
+	return common.WriteFileAtomic(filepath.Join(aliasPath, manifestFileName), content, 0644)
+}
+
+// Get returns the alias for the command
+func (m *Manifest) Get(command string) (Alias, bool) {
+	for _, alias := range m.Aliases {
+		if alias.Command == command {
+			return alias, true
+		}
+	}
+
+	return Alias{}, false
+}
+
+// Add adds the alias to the manifest, replacing a existing alias for the same command
+func (m *Manifest) Add(alias Alias) {
+	m.Remove(alias.Command)
+	m.Aliases = append(m.Aliases, alias)
+}
+
+// Remove removes the alias for the command from the manifest
+func (m *Manifest) Remove(command string) {
+	var aliases []Alias
+	for _, alias := range m.Aliases {
+		if alias.Command != command {
+			aliases = append(aliases, alias)
+		}
+	}
+	m.Aliases = aliases
+}
+
+// AliasFile returns the path of the alias file for the command
+func AliasFile(aliasPath string, command string, mode string) string {
+	if runtime.GOOS == "windows" && mode == ModeScript {
+		return filepath.Join(aliasPath, command+".cmd")
+	} else if runtime.GOOS == "windows" {
+		return filepath.Join(aliasPath, command+".exe")
+	}
+
+	return filepath.Join(aliasPath, command)
+}
+
+// Uninstall removes the alias file, a already removed file is not a error
+func Uninstall(alias Alias) error {
+	log.Debug().Str("command", alias.Command).Str("file", alias.Path).Msg("Removing alias ...")
+	if err := os.Remove(alias.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -89,7 +89,7 @@ func scriptsAliasCmd() (*asset, error) {
 	return a, nil
 }
 
-var _scriptsAliasSh = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x51\x4d\xcb\x13\x31\x10\xbe\xe7\x57\x3c\xe6\x0d\xbc\xed\x61\x5d\xbd\x5a\x16\x44\xad\x22\x88\x37\xbd\x18\x91\xec\x66\xb2\x1b\x4c\x13\xcd\xc7\x2a\xd4\xfe\x77\xc9\xb6\x5b\x8a\xd2\x53\x66\x32\xcf\xe4\xf9\xc8\xc3\x93\xb6\xa4\xd8\xf6\xd6\xb7\xe4\x67\xf4\x2a\x4d\x8c\x3d\x40\x53\x5f\x46\x1c\x82\x26\xf6\x66\xff\xea\xd3\xbb\x4e\x1c\x97\xf3\x45\x63\x94\x4b\x74\x62\xd6\xe0\x0b\xb8\x58\x2e\x39\xba\x0e\x3c\xc7\x42\x1c\x5f\x77\xc8\x13\x79\x06\x00\x89\x32\x9a\xdf\xcc\xd8\xfa\xa2\xb1\x5e\x23\x94\x8c\x5f\x93\x1d\x26\x28\x67\x55\x82\x4d\x18\x94\x73\xa4\xd9\xd2\xbf\x0d\xb1\x13\x1b\x1a\xa6\x00\xb1\xe9\x55\x22\xaf\x0e\x04\xb1\x89\xa4\xb4\xb3\xfe\x3b\x1a\x6f\x20\x9e\x6d\xb7\xf8\x83\xa1\x64\x34\x06\xcf\xd1\x68\x3c\x3e\x7d\xdc\x56\x8e\x9f\x25\x64\x82\x72\x0e\x2a\x8e\x89\x99\x10\x6b\x01\xeb\xc1\xc5\x4b\xbe\x83\x0e\x58\x84\xa9\x38\x76\x5c\x1c\x55\x1c\xdb\x56\xca\x56\x4a\x29\x4f\xfc\x3c\x71\xae\xae\x76\x5c\x5c\x2a\xc8\x2b\x90\x57\x20\x3f\x49\xce\x99\x0e\x9e\x2a\x61\x15\x0f\xf2\xf3\xe0\x2c\x2a\x5b\x9e\xe8\xe2\x4c\x79\x8d\x1f\x2a\xa5\x55\x4d\x39\x90\xcf\x89\xed\x3f\x7e\x7e\xfd\xe1\xfd\xb7\x35\xd3\xdb\xf6\xdf\x68\x6f\x67\xf7\x12\xa6\x59\x5d\xf9\x9b\xc6\x85\xd1\xd1\x4c\xae\x3b\xff\x5e\x2c\x1e\x62\xcd\x15\xab\x21\x46\x2e\xd1\x7f\xcb\x77\xb0\xc6\xb2\xbf\x01\x00\x00\xff\xff\xdf\x13\x3f\xbc\x21\x02\x00\x00")
+var _scriptsAliasSh = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\x51\x5d\x4b\xc3\x30\x14\x7d\xcf\xaf\x38\x66\x85\x6d\x0f\xb1\xfa\xea\x28\x88\x3a\x45\x10\xdf\xf4\xc5\x8a\x64\xcd\xed\x1a\xcc\x12\x6d\xda\x29\xcc\xfe\x77\x93\x6e\x1d\x43\x59\x08\xe4\x7e\x9f\x73\x4f\x46\x27\x69\xeb\xeb\x74\xa1\x6d\x4a\x76\x8d\x85\xf4\x15\x63\x23\x28\x5a\xb4\x4b\xac\x9c\x22\x76\x33\xbf\x7a\xba\xcb\x92\x4d\xff\x5e\x88\x52\x1a\x4f\x1d\xd3\x25\x5e\xc0\x93\x3e\xc8\x91\x65\xe0\x4d\xdd\x12\xc7\xeb\x0c\x4d\x45\x96\x21\x1c\x4f\x0d\xc4\x37\x2b\x75\x9c\x58\x6a\xab\xe0\xda\x06\x5f\x95\x2e\x2a\x48\xa3\xa5\x87\xf6\x28\xa4\x31\xa4\x58\xef\xdf\xba\x3a\x4b\x26\x54\x54\x0e\xc9\x24\x50\x21\x2b\x57\x14\xcc\x9a\xa4\x32\xda\xbe\x43\xd8\x12\xc9\xd9\x74\x8a\x1f\x14\x61\x94\x28\x71\x0e\xa1\x30\x3e\x1d\x4f\x23\xc6\x67\xeb\x1a\x0a\xa3\x0d\x64\xbd\xf4\xac\x74\x75\x34\xa0\x6d\x60\x7a\xc9\x67\x50\x0e\x3d\xb1\x10\xcc\x78\xb2\x09\x4f\x9a\xe6\x79\xb8\x79\xde\xf1\x6d\xc6\x98\xd8\x1a\xb2\x3b\x0b\xf9\xbe\x90\xc7\x42\xde\xe5\x9c\x33\xe5\x2c\x45\xc0\x48\x1e\x41\xb7\xc2\x68\x44\xb4\xb0\xfa\x6e\x33\x19\xb6\xfd\x90\xde\x0f\x6c\xda\x15\xd9\xc6\xb3\xf9\xe3\xf3\xf5\xc3\xfd\xdb\xa0\xe9\xa1\xfb\x57\xda\xc3\xdc\x31\x85\x69\x2d\xf7\xf8\x42\x18\xb7\x14\x86\xd6\x64\xb2\xed\xf7\xd5\xad\x45\x32\x08\x8b\x61\x23\x46\x01\xe5\x5f\xf7\x91\xda\xf0\x77\xbf\xbe\x97\xfd\x8a\x22\x02\x00\x00")
 
 func scriptsAliasShBytes() ([]byte, error) {
 	return bindataRead(
@@ -104,7 +104,7 @@ func scriptsAliasSh() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "scripts/alias.sh", size: 545, mode: os.FileMode(511), modTime: time.Unix(1570305560, 0)}
+	info := bindataFileInfo{name: "scripts/alias.sh", size: 546, mode: os.FileMode(511), modTime: time.Unix(1792420624, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
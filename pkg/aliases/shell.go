@@ -0,0 +1,116 @@
+package aliases
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+)
+
+// Shells are the shells supported by shell-init
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// validFunctionName matches commands that can be defined as shell functions in all supported shells
+var validFunctionName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.+-]*$`)
+
+// ShellInit returns the script that installs the directory-change hook, which redefines the functions on every directory change
+func ShellInit(shell string, executable string) (string, error) {
+	switch shell {
+	case "bash":
+		return `_envcli_hook() {
+  if [ "$PWD" != "$_ENVCLI_PWD" ]; then
+    _ENVCLI_PWD="$PWD"
+    eval "$(` + common.PosixQuote(executable) + ` shell-init bash --functions)"
+  fi
+}
+case ";${PROMPT_COMMAND:-};" in
+  *";_envcli_hook;"*) ;;
+  *) PROMPT_COMMAND="_envcli_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+_envcli_hook
+`, nil
+	case "zsh":
+		return `_envcli_hook() {
+  eval "$(` + common.PosixQuote(executable) + ` shell-init zsh --functions)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _envcli_hook
+_envcli_hook
+`, nil
+	case "fish":
+		return `function __envcli_hook --on-variable PWD
+    ` + fishQuote(executable) + ` shell-init fish --functions | source
+end
+__envcli_hook
+`, nil
+	case "powershell":
+		return `function global:Invoke-EnvcliHook {
+  if ($PWD.Path -ne $global:EnvcliPwd) {
+    $global:EnvcliPwd = $PWD.Path
+    (& ` + common.PowershellQuote(executable) + ` shell-init powershell --functions) -join "` + "`n" + `" | Invoke-Expression
+  }
+}
+if (-not $global:EnvcliOriginalPrompt) {
+  $global:EnvcliOriginalPrompt = $function:prompt
+  function global:prompt { Invoke-EnvcliHook; & $global:EnvcliOriginalPrompt }
+}
+Invoke-EnvcliHook
+`, nil
+	}
+
+	return "", errors.New("unsupported shell [" + shell + "], allowed: " + strings.Join(Shells, ", "))
+}
+
+// ShellFunctions returns the script that removes the functions defined on the previous call and defines a function for each command
+func ShellFunctions(shell string, executable string, commands []string) (string, error) {
+	var valid []string
+	seen := make(map[string]bool)
+	for _, command := range commands {
+		if validFunctionName.MatchString(command) && !seen[command] {
+			valid = append(valid, command)
+			seen[command] = true
+		}
+	}
+
+	var script strings.Builder
+	switch shell {
+	case "bash", "zsh":
+		list := "$_ENVCLI_FUNCTIONS"
+		remove := `unset -f "$_envcli_fn"`
+		if shell == "zsh" {
+			list = "${=_ENVCLI_FUNCTIONS}"
+			remove = `unfunction "$_envcli_fn" 2>/dev/null`
+		}
+		script.WriteString("for _envcli_fn in " + list + "; do " + remove + "; done; unset _envcli_fn\n")
+		script.WriteString("_ENVCLI_FUNCTIONS=" + common.PosixQuote(strings.Join(valid, " ")) + "\n")
+		for _, command := range valid {
+			script.WriteString(command + "() { " + common.PosixQuote(executable) + " run " + command + " \"$@\"; }\n")
+		}
+	case "fish":
+		script.WriteString("for __envcli_fn in $__envcli_functions; functions -e $__envcli_fn; end\n")
+		script.WriteString("set -g __envcli_functions " + strings.Join(valid, " ") + "\n")
+		for _, command := range valid {
+			script.WriteString("function " + command + "; " + fishQuote(executable) + " run " + command + " $argv; end\n")
+		}
+	case "powershell":
+		script.WriteString("foreach ($f in $global:EnvcliFunctions) { Remove-Item -Path \"function:global:$f\" -ErrorAction SilentlyContinue }\n")
+		var quoted []string
+		for _, command := range valid {
+			quoted = append(quoted, common.PowershellQuote(command))
+		}
+		script.WriteString("$global:EnvcliFunctions = @(" + strings.Join(quoted, ", ") + ")\n")
+		for _, command := range valid {
+			script.WriteString("function global:" + command + " { & " + common.PowershellQuote(executable) + " run " + command + " @args }\n")
+		}
+	default:
+		return "", errors.New("unsupported shell [" + shell + "], allowed: " + strings.Join(Shells, ", "))
+	}
+
+	return script.String(), nil
+}
+
+// fishQuote quotes the value for fish
+func fishQuote(value string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'"
+}
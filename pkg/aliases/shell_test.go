@@ -0,0 +1,26 @@
+package aliases
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellFunctions(t *testing.T) {
+	script, err := ShellFunctions("bash", "/opt/envcli's/envcli", []string{"go", "gofmt", "go", "bad name", "$(rm)"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(script, `go() { '/opt/envcli'\''s/envcli' run go "$@"; }`) {
+		t.Errorf("expected a quoted function for go, got:\n%s", script)
+	}
+	if !strings.Contains(script, "_ENVCLI_FUNCTIONS='go gofmt'") {
+		t.Errorf("expected the defined functions to be tracked once, got:\n%s", script)
+	}
+	if strings.Contains(script, "bad name") || strings.Contains(script, "$(rm)") {
+		t.Errorf("expected invalid function names to be skipped, got:\n%s", script)
+	}
+	if _, err := ShellFunctions("tcsh", "envcli", []string{"go"}); err == nil {
+		t.Error("expected a error for a unsupported shell")
+	}
+}
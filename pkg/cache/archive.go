@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// manifestFileName is the name of the manifest inside of cache archives
+const manifestFileName = "manifest.yml"
+
+// archiveCacheDirectory is the directory inside of cache archives that contains the caches
+const archiveCacheDirectory = "caches"
+
+// Manifest describes the content of a cache archive
+type Manifest struct {
+	Version string          `yaml:"version"`
+	Key     string          `yaml:"key"`
+	Created time.Time       `yaml:"created"`
+	Caches  []ManifestEntry `yaml:"caches"`
+}
+
+// ManifestEntry describes a single cache inside of a cache archive
+type ManifestEntry struct {
+	Name    string `yaml:"name"`
+	Cache   string `yaml:"cache"`
+	Scope   string `yaml:"scope"`
+	Project string `yaml:"project,omitempty"`
+}
+
+// ComputeKey computes the cache key from the content hashes of the files, the order of the files matters
+// files are identified by their path relative to the project directory, so the key doesn't depend on how the path is written or where the project is checked out
+func ComputeKey(projectDirectory string, files []string) (string, error) {
+	if len(files) == 0 {
+		return "", errors.New("no files to compute the cache key from")
+	}
+
+	hash := sha256.New()
+	for _, file := range files {
+		absolutePath, err := filepath.Abs(file)
+		if err != nil {
+			return "", err
+		}
+		relativePath, err := filepath.Rel(projectDirectory, absolutePath)
+		if err != nil {
+			return "", err
+		}
+		content, err := os.ReadFile(absolutePath)
+		if err != nil {
+			return "", err
+		}
+		fileHash := sha256.Sum256(content)
+		_, _ = io.WriteString(hash, filepath.ToSlash(relativePath)+"\x00"+hex.EncodeToString(fileHash[:])+"\n")
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Export writes the directory caches into a zstd compressed tar archive, the manifest is the first entry of the archive - links are not exported
+func Export(caches []Info, key string, w io.Writer) (Manifest, error) {
+	manifest := Manifest{Version: "v1", Key: key, Created: time.Now().UTC()}
+	for _, info := range caches {
+		if info.Type != TypeDirectory {
+			return Manifest{}, errors.New("cache [" + info.Name + "] is a " + info.Type + ", only directory caches can be exported")
+		}
+		manifest.Caches = append(manifest.Caches, ManifestEntry{Name: info.Name, Cache: info.Cache, Scope: info.Scope, Project: info.Project})
+	}
+
+	zstdWriter, err := zstd.NewWriter(w)
+	if err != nil {
+		return Manifest{}, err
+	}
+	tarWriter := tar.NewWriter(zstdWriter)
+
+	// manifest
+	manifestContent, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return Manifest{}, err
+	}
+	err = tarWriter.WriteHeader(&tar.Header{Name: manifestFileName, Mode: 0644, Size: int64(len(manifestContent)), ModTime: manifest.Created})
+	if err != nil {
+		return Manifest{}, err
+	}
+	if _, err := tarWriter.Write(manifestContent); err != nil {
+		return Manifest{}, err
+	}
+
+	// caches
+	for _, info := range caches {
+		log.Debug().Str("cache", info.Name).Str("dir", info.Path).Msg("adding cache to archive")
+		if err := addDirectory(tarWriter, info.Path, path.Join(archiveCacheDirectory, info.Name)); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, zstdWriter.Close()
+}
+
+// Import extracts the caches of the archive into the cache root, existing caches with the same name are replaced
+// if expectedKey is set, archives with a different key are rejected before anything is extracted
+// project scoped caches are restored with the key of the project directory, as the archive may be created in a different checkout
+func Import(cachePath string, r io.Reader, expectedKey string, projectDirectory string) (Manifest, error) {
+	zstdReader, err := zstd.NewReader(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer zstdReader.Close()
+	tarReader := tar.NewReader(zstdReader)
+
+	// manifest
+	header, err := tarReader.Next()
+	if err != nil || header.Name != manifestFileName {
+		return Manifest{}, errors.New("invalid cache archive, the manifest is missing")
+	}
+	var manifest Manifest
+	manifestContent, err := io.ReadAll(tarReader)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := yaml.Unmarshal(manifestContent, &manifest); err != nil {
+		return Manifest{}, err
+	}
+	if expectedKey != "" && manifest.Key != expectedKey {
+		return Manifest{}, errors.New("cache key mismatch, archive has key [" + manifest.Key + "] but expected [" + expectedKey + "]")
+	}
+
+	// replace caches
+	manifestCaches := make(map[string]string)
+	for i, entry := range manifest.Caches {
+		name := entry.Name
+		if entry.Scope == ScopeProject {
+			if entry.Cache == "" {
+				return Manifest{}, errors.New("invalid cache archive, project scoped cache [" + entry.Name + "] has no cache name")
+			}
+			name, _ = Key(entry.Cache, ScopeProject, projectDirectory, "")
+			manifest.Caches[i].Name, manifest.Caches[i].Project = name, projectDirectory
+		}
+
+		if _, err := CreateDirectory(cachePath, name); err != nil {
+			return Manifest{}, err
+		}
+		if err := os.RemoveAll(filepath.Join(cachePath, name)); err != nil {
+			return Manifest{}, err
+		}
+		manifestCaches[entry.Name] = name
+	}
+
+	// extract
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return Manifest{}, err
+		}
+
+		target, err := archiveTarget(cachePath, header.Name, manifestCaches)
+		if err != nil {
+			return Manifest{}, err
+		}
+
+		// links are never created, so all entries are written into real directories below the cache
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = extractFile(tarReader, target, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink, tar.TypeLink:
+			log.Warn().Str("file", header.Name).Str("target", header.Linkname).Msg("skipping link, cache archives can only contain files and directories")
+		}
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	// mark as used, so imported caches aren't pruned right away
+	var usages []Usage
+	for _, entry := range manifest.Caches {
+		usages = append(usages, Usage{Key: entry.Name, Cache: entry.Cache, Scope: entry.Scope, Project: entry.Project})
+	}
+
+	return manifest, RecordUsage(cachePath, usages, "")
+}
+
+// addDirectory adds the directory recursively to the archive below the prefix
+func addDirectory(tarWriter *tar.Writer, dir string, prefix string) error {
+	return filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, _ := filepath.Rel(dir, file)
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			log.Debug().Str("file", file).Msg("skipping file that is neither a regular file nor a directory")
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(relativePath))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			content, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer content.Close()
+			_, err = io.Copy(tarWriter, content)
+			return err
+		}
+		return nil
+	})
+}
+
+// archiveTarget returns the target path of a archive entry below the local name of its cache, entries outside of the caches listed in the manifest are rejected
+func archiveTarget(cachePath string, name string, manifestCaches map[string]string) (string, error) {
+	cleanName := path.Clean(name)
+	if !strings.HasPrefix(cleanName, archiveCacheDirectory+"/") {
+		return "", errors.New("invalid cache archive entry [" + name + "]")
+	}
+	cacheName, relativePath, _ := strings.Cut(strings.TrimPrefix(cleanName, archiveCacheDirectory+"/"), "/")
+	localName, found := manifestCaches[cacheName]
+	if !found {
+		return "", errors.New("invalid cache archive entry [" + name + "], cache [" + cacheName + "] is not listed in the manifest")
+	}
+
+	return filepath.Join(cachePath, localName, filepath.FromSlash(relativePath)), nil
+}
+
+// extractFile writes the content of the reader into the target file
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, r)
+	return err
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Disabled is the cache-path value that explicitly disables caching
+const Disabled = "off"
+
+// ScopeShared shares the cache between all projects and images
+const ScopeShared = "shared"
+
+// ScopeProject isolates the cache per project directory
+const ScopeProject = "project"
+
+// ScopeImage isolates the cache per image reference
+const ScopeImage = "image"
+
+// LockExclusive allows only a single run at a time to use the cache
+const LockExclusive = "exclusive"
+
+// lockDirectory is the directory in the cache root that contains the lock files of the caches
+const lockDirectory = ".locks"
+
+// GetCachePath returns the cache root directory and whether caching is enabled, defaults to $XDG_CACHE_HOME/envcli
+func GetCachePath(properties map[string]string) (string, bool) {
+	cachePath := strings.TrimSpace(properties["cache-path"])
+	if strings.EqualFold(cachePath, Disabled) {
+		return "", false
+	}
+	if cachePath == "" {
+		cachePath = config.GetCacheDirectory()
+	}
+
+	return cachePath, true
+}
+
+// Key returns the key of the cache, used as directory and volume name, which isolates the cache according to the scope
+func Key(name string, scope string, projectDirectory string, image string) (string, error) {
+	switch scope {
+	case "", ScopeShared:
+		return name, nil
+	case ScopeProject:
+		return name + "-project-" + shortHash(projectDirectory), nil
+	case ScopeImage:
+		return name + "-image-" + shortHash(image), nil
+	}
+
+	return "", errors.New("invalid cache scope [" + scope + "] for cache [" + name + "], allowed: shared, project, image")
+}
+
+// CreateDirectory creates the directory of a cache below the cache root, the root is only accessible by the current user
+func CreateDirectory(cachePath string, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", errors.New("invalid cache name [" + name + "]")
+	}
+
+	if err := os.MkdirAll(cachePath, 0700); err != nil {
+		return "", err
+	}
+
+	cacheFolder := filepath.Join(cachePath, name)
+	if err := os.MkdirAll(cacheFolder, 0755); err != nil {
+		return "", err
+	}
+	log.Trace().Str("dir", cacheFolder).Msg("cache directory ready")
+
+	return cacheFolder, nil
+}
+
+// Lock acquires the exclusive lock of the cache, waits until other runs using the cache have finished
+func Lock(cachePath string, key string) (*common.FileLock, error) {
+	lock := common.NewFileLock(filepath.Join(cachePath, lockDirectory, key+".lock"))
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		log.Info().Str("cache", key).Msg("waiting for the cache to be released by another run")
+		if err := lock.Lock(); err != nil {
+			return nil, err
+		}
+	}
+
+	return lock, nil
+}
+
+// shortHash returns the first 12 characters of the hex encoded sha256 hash of the value
+func shortHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])[:12]
+}
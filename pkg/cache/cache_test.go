@@ -0,0 +1,351 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestGetCachePath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+
+	if _, enabled := GetCachePath(map[string]string{"cache-path": "off"}); enabled {
+		t.Error("expected cache-path=off to disable caching")
+	}
+	if path, enabled := GetCachePath(map[string]string{"cache-path": "/data/cache"}); !enabled || path != "/data/cache" {
+		t.Errorf("expected the configured cache path, got %s", path)
+	}
+	if path, enabled := GetCachePath(nil); !enabled || path != filepath.Join("/tmp/xdg-cache", "envcli") {
+		t.Errorf("expected the xdg cache directory as default, got %s", path)
+	}
+}
+
+func TestCreateDirectory(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "envcli")
+
+	cacheFolder, err := CreateDirectory(cachePath, "golang")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(cachePath); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("expected the cache root to be private to the current user, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(cacheFolder); err != nil {
+		t.Errorf("expected the cache directory to exist: %v", err)
+	}
+	if _, err := CreateDirectory(cachePath, "../escape"); err == nil {
+		t.Error("expected a error for a cache name with path separators")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{"1024": 1024, "500M": 500 << 20, "10G": 10 << 30, "1.5k": 1536, "2GB": 2 << 30}
+	for input, expected := range cases {
+		if size, err := ParseSize(input); err != nil || size != expected {
+			t.Errorf("expected %s to be parsed as %d, got %d (%v)", input, expected, size, err)
+		}
+	}
+	if _, err := ParseSize("ten"); err == nil {
+		t.Error("expected a error for a invalid size")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour}
+	for input, expected := range cases {
+		if age, err := ParseAge(input); err != nil || age != expected {
+			t.Errorf("expected %s to be parsed as %s, got %s (%v)", input, expected, age, err)
+		}
+	}
+}
+
+func TestEvictLeastRecentlyUsed(t *testing.T) {
+	cachePath := t.TempDir()
+	for i, name := range []string{"old", "recent", "current"} {
+		cacheFolder, _ := CreateDirectory(cachePath, name)
+		_ = os.WriteFile(filepath.Join(cacheFolder, "data"), make([]byte, 1000), 0644)
+		_ = RecordUsage(cachePath, []Usage{{Key: name, Cache: name, Scope: ScopeShared}}, "image-"+name)
+		index, _ := LoadIndex(cachePath)
+		entry := index.Caches[name]
+		entry.LastUsed = time.Now().Add(time.Duration(i-3) * time.Hour)
+		index.Caches[name] = entry
+		_ = SaveIndex(cachePath, index)
+	}
+
+	evicted, err := Evict(cachePath, 1500, []string{"current"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(evicted) != 2 || evicted[0] != "old" || evicted[1] != "recent" {
+		t.Errorf("expected old and recent to be evicted, got %v", evicted)
+	}
+	if _, err := os.Stat(filepath.Join(cachePath, "current")); err != nil {
+		t.Error("expected the cache in use to be kept")
+	}
+}
+
+func TestKeyIsolatesScopes(t *testing.T) {
+	shared, _ := Key("golang", ScopeShared, "/src/a", "golang:1.20")
+	projectA, _ := Key("golang", ScopeProject, "/src/a", "golang:1.20")
+	projectB, _ := Key("golang", ScopeProject, "/src/b", "golang:1.20")
+	image120, _ := Key("golang", ScopeImage, "/src/a", "golang:1.20")
+	image121, _ := Key("golang", ScopeImage, "/src/a", "golang:1.21")
+
+	if shared != "golang" {
+		t.Errorf("expected shared caches to use the plain name, got %s", shared)
+	}
+	if projectA == projectB || image120 == image121 || projectA == shared || image120 == shared {
+		t.Errorf("expected isolated cache keys, got %s, %s, %s, %s", projectA, projectB, image120, image121)
+	}
+	if _, err := Key("golang", "global", "/src/a", "golang:1.20"); err == nil {
+		t.Error("expected a error for a invalid scope")
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := t.TempDir()
+	cacheFolder, _ := CreateDirectory(source, "npm")
+	_ = os.MkdirAll(filepath.Join(cacheFolder, "pkg"), 0755)
+	_ = os.WriteFile(filepath.Join(cacheFolder, "pkg", "index.json"), []byte("{}"), 0644)
+
+	lockFile := filepath.Join(t.TempDir(), "package-lock.json")
+	_ = os.WriteFile(lockFile, []byte("lock"), 0644)
+	key, err := ComputeKey(filepath.Dir(lockFile), []string{lockFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var archive bytes.Buffer
+	caches := []Info{{Name: "npm", Cache: "npm", Scope: ScopeShared, Type: TypeDirectory, Path: cacheFolder}}
+	if _, err := Export(caches, key, &archive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target := t.TempDir()
+	if _, err := Import(target, bytes.NewReader(archive.Bytes()), "other", "/src/app"); err == nil {
+		t.Error("expected a error for a key mismatch")
+	}
+	manifest, err := Import(target, bytes.NewReader(archive.Bytes()), key, "/src/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Key != key || len(manifest.Caches) != 1 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if content, err := os.ReadFile(filepath.Join(target, "npm", "pkg", "index.json")); err != nil || string(content) != "{}" {
+		t.Errorf("expected the cache content to be restored, got %q (%v)", content, err)
+	}
+}
+
+func TestComputeKey(t *testing.T) {
+	projectA, projectB := t.TempDir(), t.TempDir()
+	for _, dir := range []string{projectA, projectB} {
+		_ = os.MkdirAll(filepath.Join(dir, "web"), 0755)
+		_ = os.WriteFile(filepath.Join(dir, "go.sum"), []byte("sum"), 0644)
+		_ = os.WriteFile(filepath.Join(dir, "web", "package-lock.json"), []byte("lock"), 0644)
+	}
+
+	key, err := ComputeKey(projectA, []string{filepath.Join(projectA, "go.sum"), filepath.Join(projectA, "web", "package-lock.json")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workingDirectory, _ := os.Getwd()
+	relativeSum, _ := filepath.Rel(workingDirectory, filepath.Join(projectA, "go.sum"))
+	relativeLock, _ := filepath.Rel(workingDirectory, filepath.Join(projectA, "web", "package-lock.json"))
+	variants := map[string][]string{
+		"relative paths":    {relativeSum, "./" + relativeLock},
+		"unclean paths":     {projectA + "/./go.sum", projectA + "/web/../web/package-lock.json"},
+		"different project": {filepath.Join(projectB, "go.sum"), filepath.Join(projectB, "web", "package-lock.json")},
+	}
+	for name, files := range variants {
+		project := projectA
+		if name == "different project" {
+			project = projectB
+		}
+		if variant, err := ComputeKey(project, files); err != nil || variant != key {
+			t.Errorf("%s: expected the key %s, got %s (%v)", name, key, variant, err)
+		}
+	}
+
+	// the key changes with the content and the order of the files
+	_ = os.WriteFile(filepath.Join(projectB, "web", "package-lock.json"), []byte("changed"), 0644)
+	if changed, _ := ComputeKey(projectB, []string{filepath.Join(projectB, "go.sum"), filepath.Join(projectB, "web", "package-lock.json")}); changed == key {
+		t.Error("expected the content to change the key")
+	}
+	if reordered, _ := ComputeKey(projectA, []string{filepath.Join(projectA, "web", "package-lock.json"), filepath.Join(projectA, "go.sum")}); reordered == key {
+		t.Error("expected the order of the files to change the key")
+	}
+
+	if _, err := ComputeKey(projectA, nil); err == nil {
+		t.Error("expected a error without files")
+	}
+}
+
+func TestImportProjectScope(t *testing.T) {
+	exportKey, _ := Key("go-build", ScopeProject, "/builds/runner-1/app", "")
+	source := t.TempDir()
+	cacheFolder, _ := CreateDirectory(source, exportKey)
+	_ = os.WriteFile(filepath.Join(cacheFolder, "entry"), []byte("cached"), 0644)
+
+	var archive bytes.Buffer
+	caches := []Info{{Name: exportKey, Cache: "go-build", Scope: ScopeProject, Project: "/builds/runner-1/app", Type: TypeDirectory, Path: cacheFolder}}
+	if _, err := Export(caches, "", &archive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target := t.TempDir()
+	manifest, err := Import(target, bytes.NewReader(archive.Bytes()), "", "/builds/runner-2/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	importKey, _ := Key("go-build", ScopeProject, "/builds/runner-2/app", "")
+	if manifest.Caches[0].Name != importKey || manifest.Caches[0].Project != "/builds/runner-2/app" {
+		t.Errorf("expected the cache to be imported for the current project, got %+v", manifest.Caches[0])
+	}
+	if content, err := os.ReadFile(filepath.Join(target, importKey, "entry")); err != nil || string(content) != "cached" {
+		t.Errorf("expected the cache content below the key of the current project, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(target, exportKey)); err == nil {
+		t.Error("expected the key of the exporting project to not be used")
+	}
+}
+
+// testArchiveEntry is a entry of a hand crafted cache archive
+type testArchiveEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	content  string
+}
+
+func writeTestArchive(t *testing.T, manifest string, entries []testArchiveEntry) []byte {
+	var archive bytes.Buffer
+	zstdWriter, _ := zstd.NewWriter(&archive)
+	tarWriter := tar.NewWriter(zstdWriter)
+	entries = append([]testArchiveEntry{{name: manifestFileName, typeflag: tar.TypeReg, content: manifest}}, entries...)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0755, Size: int64(len(entry.content))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = tarWriter.Close()
+	_ = zstdWriter.Close()
+
+	return archive.Bytes()
+}
+
+func TestImportChainedSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "cache")
+	archive := writeTestArchive(t, "version: v1\ncaches:\n- name: key\n", []testArchiveEntry{
+		{name: "caches/key/d/", typeflag: tar.TypeDir},
+		{name: "caches/key/d/s1", typeflag: tar.TypeSymlink, linkname: ".."},
+		{name: "caches/key/d/s1/s2", typeflag: tar.TypeSymlink, linkname: "../.."},
+		{name: "caches/key/d/s1/s2/escape.txt", typeflag: tar.TypeReg, content: "escaped"},
+		{name: "caches/key/d/h", typeflag: tar.TypeLink, linkname: "/etc/passwd"},
+	})
+
+	if _, err := Import(target, bytes.NewReader(archive), "", "/src/app"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); err == nil {
+		t.Error("expected no file to be written outside of the cache")
+	}
+	err := filepath.Walk(target, func(file string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			t.Errorf("expected links to be skipped, found %s", file)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(filepath.Join(target, "key", "d", "s1", "s2", "escape.txt")); err != nil || string(content) != "escaped" {
+		t.Errorf("expected the file to be written into real directories below the cache, got %q (%v)", content, err)
+	}
+}
+
+func TestImportRejectsUnlistedCaches(t *testing.T) {
+	cases := []struct {
+		manifest string
+		entry    string
+	}{
+		{"version: v1\ncaches:\n- name: key\n", "caches/other/file"},
+		{"version: v1\ncaches:\n- name: key\n", "caches/key/../../file"},
+		{"version: v1\ncaches:\n- name: ../x\n", "caches/../x/file"},
+	}
+	for _, c := range cases {
+		root := t.TempDir()
+		archive := writeTestArchive(t, c.manifest, []testArchiveEntry{{name: c.entry, typeflag: tar.TypeReg, content: "x"}})
+		if _, err := Import(filepath.Join(root, "cache"), bytes.NewReader(archive), "", "/src/app"); err == nil {
+			t.Errorf("expected a error for the entry %s", c.entry)
+		}
+		if _, err := os.Stat(filepath.Join(root, "file")); err == nil {
+			t.Errorf("expected no file to be written outside of the cache for the entry %s", c.entry)
+		}
+	}
+}
+
+// fakeVolumes replaces the container runtime with a fake that serves the volumes with their creation time and records removed volumes
+func fakeVolumes(t *testing.T, volumes map[string]string) *[]string {
+	var removed []string
+	originalDetect, originalOutput := detectRuntime, runtimeOutput
+	detectRuntime = func() (string, error) { return "docker", nil }
+	runtimeOutput = func(args ...string) (string, error) {
+		switch args[1] {
+		case "ls":
+			var names []string
+			for name := range volumes {
+				names = append(names, name)
+			}
+			return strings.Join(names, "\n"), nil
+		case "inspect":
+			volumeName := args[len(args)-1]
+			return strings.TrimPrefix(volumeName, volumePrefix) + "\t" + volumes[volumeName], nil
+		case "rm":
+			removed = append(removed, args[2])
+		}
+		return "", nil
+	}
+	t.Cleanup(func() {
+		detectRuntime, runtimeOutput = originalDetect, originalOutput
+	})
+
+	return &removed
+}
+
+func TestPruneVolumes(t *testing.T) {
+	cachePath := t.TempDir()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	removed := fakeVolumes(t, map[string]string{
+		"envcli-cache-old":     old.UTC().Format(time.RFC3339),
+		"envcli-cache-podman":  old.Format("2006-01-02 15:04:05.999999999 -0700 MST"),
+		"envcli-cache-new":     time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		"envcli-cache-unknown": "yesterday",
+		"envcli-cache-used":    old.UTC().Format(time.RFC3339),
+	})
+	if err := RecordUsage(cachePath, []Usage{{Key: "envcli-cache-used", Cache: "used"}}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := Prune(cachePath, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(*removed)
+	if expected := []string{"envcli-cache-old", "envcli-cache-podman"}; !reflect.DeepEqual(*removed, expected) {
+		t.Errorf("expected only the volumes created before the duration to be removed, got %v (%v)", *removed, pruned)
+	}
+}
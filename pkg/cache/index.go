@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// indexFileName is the name of the file that tracks the cache usage, stored in the cache root
+const indexFileName = ".envcli-index.yml"
+
+// indexLockFileName is the name of the lock file that serializes index updates of concurrent runs
+const indexLockFileName = ".envcli-index.lock"
+
+// Index tracks when caches have been used and by which images
+type Index struct {
+	Caches map[string]IndexEntry `yaml:"caches"`
+}
+
+// IndexEntry holds the usage of a single cache
+type IndexEntry struct {
+	Cache    string    `yaml:"cache"`
+	Scope    string    `yaml:"scope"`
+	Project  string    `yaml:"project,omitempty"`
+	LastUsed time.Time `yaml:"lastUsed"`
+	Images   []string  `yaml:"images"`
+}
+
+// Usage identifies a cache that is used by a run
+type Usage struct {
+	// Key is the directory name or the volume name of the cache
+	Key     string
+	Cache   string
+	Scope   string
+	Project string
+}
+
+// LoadIndex loads the cache index, a missing index results in a empty index
+func LoadIndex(cachePath string) (Index, error) {
+	index := Index{Caches: make(map[string]IndexEntry)}
+
+	content, err := os.ReadFile(filepath.Join(cachePath, indexFileName))
+	if os.IsNotExist(err) {
+		return index, nil
+	} else if err != nil {
+		return index, err
+	}
+
+	err = yaml.Unmarshal(content, &index)
+	if index.Caches == nil {
+		index.Caches = make(map[string]IndexEntry)
+	}
+
+	return index, err
+}
+
+// SaveIndex saves the cache index, the file is replaced atomically
+func SaveIndex(cachePath string, index Index) error {
+	content, err := yaml.Marshal(&index)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cachePath, 0700); err != nil {
+		return err
+	}
+
+	return common.WriteFileAtomic(filepath.Join(cachePath, indexFileName), content, 0600)
+}
+
+// updateIndex loads, modifies and saves the cache index while holding the index lock
+func updateIndex(cachePath string, update func(index *Index)) error {
+	lock := common.NewFileLock(filepath.Join(cachePath, indexLockFileName))
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	index, err := LoadIndex(cachePath)
+	if err != nil {
+		log.Warn().Err(err).Msg("cache index is corrupt, recreating it")
+	}
+	update(&index)
+
+	return SaveIndex(cachePath, index)
+}
+
+// RecordUsage marks the caches as used by the image
+func RecordUsage(cachePath string, usages []Usage, image string) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	return updateIndex(cachePath, func(index *Index) {
+		for _, usage := range usages {
+			entry := index.Caches[usage.Key]
+			entry.Cache = usage.Cache
+			entry.Scope = usage.Scope
+			entry.Project = usage.Project
+			entry.LastUsed = time.Now()
+			if isKnown, _ := collection.InArray(image, entry.Images); image != "" && !isKnown {
+				entry.Images = append(entry.Images, image)
+				sort.Strings(entry.Images)
+			}
+			index.Caches[usage.Key] = entry
+		}
+	})
+}
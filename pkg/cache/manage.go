@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// Info describes a single cache
+type Info struct {
+	// Name is the cache key, the cache name including the scope suffix
+	Name string
+	// Cache is the name of the cache in the configuration
+	Cache string
+	Scope string
+	// Project is the project directory of project scoped caches
+	Project string
+	// Type is the cache backend, directory or volume
+	Type string
+	// Path is the host directory or the name of the volume
+	Path string
+	// Size in bytes, -1 if unknown
+	Size     int64
+	LastUsed time.Time
+	Images   []string
+}
+
+// List returns all caches in the cache root and all labelled cache volumes, sorted by name
+func List(cachePath string) ([]Info, error) {
+	index, err := LoadIndex(cachePath)
+	if err != nil {
+		log.Warn().Err(err).Msg("cache index is corrupt, ignoring it")
+	}
+
+	// directories
+	entries, err := os.ReadDir(cachePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var caches []Info
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info := Info{Name: entry.Name(), Cache: entry.Name(), Scope: ScopeShared, Type: TypeDirectory, Path: filepath.Join(cachePath, entry.Name())}
+		info.Size, _ = directorySize(info.Path)
+		if indexEntry, ok := index.Caches[info.Name]; ok {
+			info.applyIndexEntry(indexEntry)
+		} else if stat, err := entry.Info(); err == nil {
+			info.LastUsed = stat.ModTime()
+		}
+		caches = append(caches, info)
+	}
+
+	// volumes
+	if _, err := detectRuntime(); err == nil {
+		volumes, err := listVolumes()
+		if err != nil {
+			return nil, err
+		}
+		for volumeName, v := range volumes {
+			// volumes without index entry were created before the index existed or with a different cache-path, their creation time is the best guess
+			info := Info{Name: strings.TrimPrefix(volumeName, volumePrefix), Cache: v.cache, Scope: ScopeShared, Type: TypeVolume, Path: volumeName, Size: -1, LastUsed: v.created}
+			if indexEntry, ok := index.Caches[volumeName]; ok {
+				info.applyIndexEntry(indexEntry)
+			}
+			caches = append(caches, info)
+		}
+	} else {
+		log.Debug().Err(err).Msg("skipping cache volumes")
+	}
+
+	sort.Slice(caches, func(i, j int) bool {
+		if caches[i].Name == caches[j].Name {
+			return caches[i].Type < caches[j].Type
+		}
+		return caches[i].Name < caches[j].Name
+	})
+
+	return caches, nil
+}
+
+func (info *Info) applyIndexEntry(entry IndexEntry) {
+	if entry.Cache != "" {
+		info.Cache = entry.Cache
+	}
+	if entry.Scope != "" {
+		info.Scope = entry.Scope
+	}
+	info.Project = entry.Project
+	info.LastUsed = entry.LastUsed
+	info.Images = entry.Images
+}
+
+// Clear removes all cache directories and volumes with the name or key, they will be recreated on next use
+func Clear(cachePath string, name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return errors.New("invalid cache name [" + name + "]")
+	}
+
+	caches, err := List(cachePath)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, info := range caches {
+		if info.Name != name && info.Cache != name {
+			continue
+		}
+		found = true
+		if err := remove(cachePath, info); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.New("cache [" + name + "] does not exist")
+	}
+
+	return nil
+}
+
+// Prune removes all caches that haven't been used within the duration, caches without a known last use are kept
+func Prune(cachePath string, olderThan time.Duration) ([]string, error) {
+	caches, err := List(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, info := range caches {
+		if info.LastUsed.IsZero() {
+			log.Debug().Str("cache", info.Name).Msg("keeping cache, the last use is unknown")
+			continue
+		}
+		if time.Since(info.LastUsed) < olderThan {
+			continue
+		}
+		if err := remove(cachePath, info); err != nil {
+			return removed, err
+		}
+		removed = append(removed, info.Name)
+	}
+
+	return removed, nil
+}
+
+// Evict removes the least recently used caches until the total size is below maxSize, caches in keep are never removed
+func Evict(cachePath string, maxSize int64, keep []string) ([]string, error) {
+	caches, err := List(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalSize int64
+	for _, info := range caches {
+		if info.Size > 0 {
+			totalSize += info.Size
+		}
+	}
+	sort.Slice(caches, func(i, j int) bool {
+		return caches[i].LastUsed.Before(caches[j].LastUsed)
+	})
+
+	var removed []string
+	for _, info := range caches {
+		if totalSize <= maxSize {
+			break
+		}
+		if isKept, _ := collection.InArray(info.Name, keep); isKept || info.Size <= 0 {
+			continue
+		}
+		if err := remove(cachePath, info); err != nil {
+			return removed, err
+		}
+		totalSize -= info.Size
+		removed = append(removed, info.Name)
+	}
+
+	return removed, nil
+}
+
+// remove deletes the cache directory or volume and its index entry
+func remove(cachePath string, info Info) error {
+	indexKey := info.Name
+	if info.Type == TypeVolume {
+		indexKey = info.Path
+		if err := removeVolume(info.Path); err != nil {
+			return err
+		}
+	} else {
+		log.Debug().Str("cache", info.Name).Str("dir", info.Path).Msg("removing cache")
+		if err := os.RemoveAll(info.Path); err != nil {
+			return err
+		}
+	}
+
+	return updateIndex(cachePath, func(index *Index) {
+		delete(index.Caches, indexKey)
+	})
+}
+
+// ParseSize parses sizes like 500M, 10G or 1024 (bytes)
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(value), "B"))
+	multiplier := int64(1)
+	for i, unit := range []string{"K", "M", "G", "T"} {
+		if strings.HasSuffix(value, unit) {
+			multiplier = int64(1) << (10 * (i + 1))
+			value = strings.TrimSuffix(value, unit)
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, errors.New("invalid size [" + value + "], expected a value like 500M or 10G")
+	}
+
+	return int64(size * float64(multiplier)), nil
+}
+
+// FormatSize formats the size in bytes as human readable string
+func FormatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	value := float64(size)
+	unit := ""
+	for _, u := range []string{"K", "M", "G", "T"} {
+		value /= 1024
+		unit = u
+		if value < 1024 {
+			break
+		}
+	}
+
+	return fmt.Sprintf("%.1f%s", value, unit)
+}
+
+// ParseAge parses durations like 30d, 2w or any value supported by time.ParseDuration
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			amount, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil {
+				return 0, errors.New("invalid duration [" + value + "]")
+			}
+			return time.Duration(amount) * unit, nil
+		}
+	}
+
+	return time.ParseDuration(value)
+}
+
+func directorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/rs/zerolog/log"
+)
+
+// TypeDirectory stores the cache in a host directory below the cache root
+const TypeDirectory = "directory"
+
+// TypeVolume stores the cache in a named volume managed by the container runtime
+const TypeVolume = "volume"
+
+// VolumeLabel is the label that marks named volumes created by envcli, the value is the cache name
+const VolumeLabel = "io.envcli.cache"
+
+// volumePrefix is the prefix of all named volumes created by envcli
+const volumePrefix = "envcli-cache-"
+
+// detectRuntime and runtimeOutput detect and execute the container runtime, replaced in tests
+var (
+	detectRuntime = common.DetectContainerRuntime
+	runtimeOutput = common.RuntimeOutput
+)
+
+// createdAtLayouts are the formats of the volume creation time, docker uses RFC3339 and podman the default go time format
+var createdAtLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"}
+
+// volume is a labelled cache volume
+type volume struct {
+	// cache is the cache name of the volume label
+	cache   string
+	created time.Time
+}
+
+// VolumeName returns the name of the named volume for the cache key
+func VolumeName(key string) string {
+	return volumePrefix + key
+}
+
+// EnsureVolume creates the named volume for the cache key labelled with the cache name, if it doesn't exist yet
+func EnsureVolume(key string, name string) (string, error) {
+	volumeName := VolumeName(key)
+	if _, err := runtimeOutput("volume", "inspect", volumeName); err == nil {
+		return volumeName, nil
+	}
+
+	log.Debug().Str("volume", volumeName).Msg("creating cache volume")
+	if _, err := runtimeOutput("volume", "create", "--label", VolumeLabel+"="+name, volumeName); err != nil {
+		return "", err
+	}
+
+	return volumeName, nil
+}
+
+// listVolumes returns all labelled cache volumes, mapped by volume name
+func listVolumes() (map[string]volume, error) {
+	output, err := runtimeOutput("volume", "ls", "--filter", "label="+VolumeLabel, "--format", "{{.Name}}")
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make(map[string]volume)
+	for _, volumeName := range strings.Fields(output) {
+		v := volume{cache: strings.TrimPrefix(volumeName, volumePrefix)}
+		inspect, err := runtimeOutput("volume", "inspect", "--format", "{{ index .Labels \""+VolumeLabel+"\" }}\t{{ .CreatedAt }}", volumeName)
+		if err == nil {
+			name, createdAt, _ := strings.Cut(inspect, "\t")
+			if name != "" {
+				v.cache = name
+			}
+			v.created = parseCreatedAt(createdAt)
+		}
+		volumes[volumeName] = v
+	}
+
+	return volumes, nil
+}
+
+// parseCreatedAt parses the creation time of a volume, zero if the format is unknown
+func parseCreatedAt(value string) time.Time {
+	for _, layout := range createdAtLayouts {
+		if created, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return created
+		}
+	}
+
+	return time.Time{}
+}
+
+// removeVolume removes the named volume
+func removeVolume(volumeName string) error {
+	log.Debug().Str("volume", volumeName).Msg("removing cache volume")
+	_, err := runtimeOutput("volume", "rm", volumeName)
+	return err
+}
@@ -0,0 +1,126 @@
+package catalog
+
+import (
+	_ "embed"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// versionPlaceholder is replaced by the requested version in the image of a tool
+const versionPlaceholder = "{version}"
+
+//go:embed catalog.yml
+var embeddedCatalog []byte
+
+// Catalog is a collection of image templates
+type Catalog struct {
+	Tools []Tool `yaml:"tools"`
+}
+
+// Tool is the template of a image entry
+type Tool struct {
+	config.RunConfigurationEntry `yaml:",inline"`
+
+	// search terms in addition to the name, description and provided commands
+	Keywords []string `yaml:"keywords"`
+
+	// version used if no version is requested
+	Version string `yaml:"version"`
+}
+
+// Load returns the embedded catalog, extended by the local catalog file if the path is not empty - local tools replace embedded tools with the same name
+func Load(catalogPath string) (Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(embeddedCatalog, &catalog); err != nil {
+		return catalog, err
+	}
+	if catalogPath == "" {
+		return catalog, nil
+	}
+
+	content, err := os.ReadFile(catalogPath)
+	if err != nil {
+		return catalog, err
+	}
+	var local Catalog
+	if err := yaml.Unmarshal(content, &local); err != nil {
+		return catalog, errors.New("invalid catalog file " + catalogPath + ": " + err.Error())
+	}
+	for _, tool := range local.Tools {
+		catalog.add(tool)
+	}
+
+	return catalog, nil
+}
+
+// add adds the tool or replaces the tool with the same name
+func (c *Catalog) add(tool Tool) {
+	for i := range c.Tools {
+		if c.Tools[i].Name == tool.Name {
+			c.Tools[i] = tool
+			return
+		}
+	}
+	c.Tools = append(c.Tools, tool)
+}
+
+// Get returns the tool with the name
+func (c Catalog) Get(name string) (Tool, bool) {
+	for _, tool := range c.Tools {
+		if strings.EqualFold(tool.Name, name) {
+			return tool, true
+		}
+	}
+
+	return Tool{}, false
+}
+
+// Search returns the tools whose name, provided commands, keywords or description contain the term, sorted by name
+func (c Catalog) Search(term string) []Tool {
+	term = strings.ToLower(term)
+	var result []Tool
+	for _, tool := range c.Tools {
+		fields := append([]string{tool.Name, tool.Description}, tool.Provides...)
+		fields = append(fields, tool.Keywords...)
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), term) {
+				result = append(result, tool)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// Entry returns the image entry of the tool for the version, the default version is used if the version is empty
+func (t Tool) Entry(version string) (config.RunConfigurationEntry, error) {
+	entry := t.RunConfigurationEntry
+	if version == "" {
+		version = t.Version
+	}
+	if !strings.Contains(entry.Image, versionPlaceholder) {
+		if version != t.Version {
+			return entry, errors.New("the image of " + t.Name + " doesn't support versions")
+		}
+		return entry, nil
+	}
+	if version == "" {
+		return entry, errors.New("no version specified for " + t.Name)
+	}
+
+	entry.Image = strings.ReplaceAll(entry.Image, versionPlaceholder, version)
+	return entry, nil
+}
+
+// ParseReference splits a tool reference (ex. node@20) into the name and the version
+func ParseReference(reference string) (string, string) {
+	name, version, _ := strings.Cut(reference, "@")
+	return name, version
+}
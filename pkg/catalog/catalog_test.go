@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWithLocalCatalog(t *testing.T) {
+	catalogFile := filepath.Join(t.TempDir(), "catalog.yml")
+	_ = os.WriteFile(catalogFile, []byte(`tools:
+- name: node
+  provides: [node]
+  image: mirror.corp/node:{version}
+  version: "20"
+- name: mytool
+  description: internal tool
+  provides: [mytool]
+  image: mirror.corp/mytool:1.0.0
+`), 0644)
+
+	toolCatalog, err := Load(catalogFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	node, _ := toolCatalog.Get("node")
+	if entry, _ := node.Entry(""); entry.Image != "mirror.corp/node:20" {
+		t.Errorf("expected the local catalog to replace node, got %s", entry.Image)
+	}
+	if _, found := toolCatalog.Get("kubectl"); !found {
+		t.Error("expected the embedded tools to be available")
+	}
+	if tools := toolCatalog.Search("INTERNAL"); len(tools) != 1 || tools[0].Name != "mytool" {
+		t.Errorf("expected to find mytool by its description, got %v", tools)
+	}
+}
+
+func TestToolEntry(t *testing.T) {
+	toolCatalog, _ := Load("")
+	golang, _ := toolCatalog.Get("go")
+
+	if entry, _ := golang.Entry("1.21"); entry.Image != "docker.io/golang:1.21" {
+		t.Errorf("unexpected image %s", entry.Image)
+	}
+	if entry, _ := golang.Entry(""); entry.Image != "docker.io/golang:1" {
+		t.Errorf("expected the default version, got %s", entry.Image)
+	}
+
+	name, version := ParseReference("node@20")
+	if name != "node" || version != "20" {
+		t.Errorf("unexpected reference %s %s", name, version)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/catalog"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/scaffold"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add <tool>[@version]",
+	Short: "adds a tool of the catalog to the project configuration",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, version := catalog.ParseReference(args[0])
+		tool, found := loadCatalog().Get(name)
+		if !found {
+			log.Fatal().Str("tool", name).Msg("tool not found in the catalog, use envcli search to find available tools")
+		}
+		entry, err := tool.Entry(version)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid tool version")
+		}
+
+		// the project configuration, or a new configuration in the current directory
+		configFile := filepath.Join(filesystem.GetWorkingDirectory(), ".envcli.yml")
+		if projectDirectory, err := config.GetProjectDirectory(); err == nil {
+			configFile = filepath.Join(projectDirectory, ".envcli.yml")
+		}
+
+		content, err := os.ReadFile(configFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatal().Err(err).Str("file", configFile).Msg("failed to read configuration file")
+		}
+		projectConfig, _ := config.LoadProjectConfig(configFile)
+		for _, element := range projectConfig.Images {
+			for _, command := range element.Provides {
+				for _, providedCommand := range entry.Provides {
+					if command == providedCommand {
+						log.Fatal().Str("command", command).Str("image", element.Name).Msg("command is already provided by the project configuration")
+					}
+				}
+			}
+		}
+
+		updated, err := scaffold.InsertEntry(string(content), entry)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", configFile).Msg("failed to add the tool")
+		}
+		if err := os.WriteFile(configFile, []byte(updated), 0644); err != nil {
+			log.Fatal().Err(err).Str("file", configFile).Msg("failed to write configuration file")
+		}
+
+		fmt.Printf("Added %s (%s) to %s, provides: %s\n", entry.Name, entry.Image, configFile, strings.Join(entry.Provides, ", "))
+	},
+}
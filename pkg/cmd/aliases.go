@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/aliases"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(aliasesCmd)
+	aliasesCmd.AddCommand(aliasesListCmd)
+	aliasesCmd.AddCommand(aliasesUninstallCmd)
+	aliasesCmd.AddCommand(aliasesSyncCmd)
+	aliasesSyncCmd.Flags().String("mode", aliases.ModeScript, "Kind of alias for missing aliases - allowed: script, symlink, hardlink")
+	aliasesSyncCmd.Flags().String("on-conflict", "overwrite", "What to do if a missing alias shadows a host binary - allowed: skip, overwrite (with a warning), fail")
+}
+
+var aliasesCmd = &cobra.Command{
+	Use:     "aliases",
+	Short:   "manages the aliases installed by install-aliases",
+	Aliases: []string{},
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+		os.Exit(0)
+	},
+}
+
+var aliasesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists all installed aliases",
+	Run: func(cmd *cobra.Command, args []string) {
+		aliasPath := aliases.GetAliasPath(propConfig.Properties)
+		manifest := loadAliasManifest(aliasPath)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMMAND\tSCOPE\tPROJECT\tMODE\tVERSION\tPATH")
+		for _, alias := range manifest.Aliases {
+			project := alias.Project
+			if project == "" {
+				project = "-"
+			}
+			mode := alias.Mode
+			if mode == "" {
+				mode = aliases.ModeScript
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", alias.Command, alias.Scope, project, mode, alias.Version, alias.Path)
+		}
+		_ = w.Flush()
+	},
+}
+
+var aliasesUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "removes the specified aliases, or all aliases if no command is specified",
+	Run: func(cmd *cobra.Command, args []string) {
+		aliasPath := aliases.GetAliasPath(propConfig.Properties)
+		manifest := loadAliasManifest(aliasPath)
+
+		var selected []aliases.Alias
+		if len(args) == 0 {
+			selected = manifest.Aliases
+		}
+		for _, command := range args {
+			alias, ok := manifest.Get(command)
+			if !ok {
+				log.Fatal().Str("command", command).Msg("no alias installed for command")
+			}
+			selected = append(selected, alias)
+		}
+
+		for _, alias := range selected {
+			if err := aliases.Uninstall(alias); err != nil {
+				log.Fatal().Err(err).Str("command", alias.Command).Msg("failed to remove alias")
+			}
+			manifest.Remove(alias.Command)
+			fmt.Printf("Removed alias %s.\n", alias.Command)
+		}
+
+		if err := aliases.SaveManifest(aliasPath, manifest); err != nil {
+			log.Fatal().Err(err).Msg("failed to save alias manifest")
+		}
+	},
+}
+
+var aliasesSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "installs missing aliases and removes aliases for commands that are no longer provided",
+	Run: func(cmd *cobra.Command, args []string) {
+		mode, _ := cmd.Flags().GetString("mode")
+		requireAliasMode(mode)
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		aliasPath := aliases.GetAliasPath(propConfig.Properties)
+
+		configured, err := configuredAliases(aliasPath, "all", mode)
+		if err != nil {
+			log.Error().Err(err).Msg("Can't sync aliases, no valid configuration was found!")
+			os.Exit(1)
+		}
+		manifest := loadAliasManifest(aliasPath)
+		var configuredCommands []string
+		for _, alias := range configured {
+			configuredCommands = append(configuredCommands, alias.Command)
+		}
+
+		// remove orphaned aliases
+		for _, alias := range manifest.Aliases {
+			if isConfigured, _ := collection.InArray(alias.Command, configuredCommands); isConfigured || !isOrphanedAlias(alias) {
+				continue
+			}
+			if err := aliases.Uninstall(alias); err != nil {
+				log.Fatal().Err(err).Str("command", alias.Command).Msg("failed to remove alias")
+			}
+			manifest.Remove(alias.Command)
+			fmt.Printf("Removed alias %s.\n", alias.Command)
+		}
+
+		// install missing aliases
+		var missing []aliases.Alias
+		for _, alias := range configured {
+			if installed, ok := manifest.Get(alias.Command); ok && !isOrphanedAlias(installed) {
+				if _, err := os.Stat(installed.Path); err == nil {
+					continue
+				}
+			}
+			missing = append(missing, alias)
+		}
+		installed := installAliases(aliasPath, resolveAliasConflicts(aliasPath, missing, onConflict), &manifest)
+		fmt.Printf("Installed %d missing aliases into %s.\n", installed, aliasPath)
+
+		if err := aliases.SaveManifest(aliasPath, manifest); err != nil {
+			log.Fatal().Err(err).Msg("failed to save alias manifest")
+		}
+	},
+}
+
+// loadAliasManifest loads the alias manifest or exits if it can't be read
+func loadAliasManifest(aliasPath string) aliases.Manifest {
+	manifest, err := aliases.LoadManifest(aliasPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load alias manifest")
+	}
+
+	return manifest
+}
+
+// isOrphanedAlias checks if the command of the alias is no longer provided by the configuration it was installed from
+func isOrphanedAlias(alias aliases.Alias) bool {
+	if alias.Scope != "Project" {
+		configured, err := configuredAliases("", "global", "")
+		if err != nil {
+			log.Warn().Err(err).Str("command", alias.Command).Msg("can't check the alias, keeping it")
+			return false
+		}
+		for _, globalAlias := range configured {
+			if globalAlias.Command == alias.Command {
+				return false
+			}
+		}
+		return true
+	}
+
+	projectConfig, err := config.LoadProjectConfig(alias.Project + "/.envcli.yml")
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	} else if err != nil {
+		log.Warn().Err(err).Str("command", alias.Command).Msg("can't check the alias, keeping it")
+		return false
+	}
+	for _, element := range projectConfig.Images {
+		if provides, _ := collection.InArray(alias.Command, element.Provides); provides && element.Alias != "false" {
+			return false
+		}
+	}
+
+	return true
+}
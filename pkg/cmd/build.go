@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+}
+
+var buildCmd = &cobra.Command{
+	Use:     "build",
+	Short:   "rebuilds the images of commands that are built from a Dockerfile",
+	Aliases: []string{},
+	Run: func(cmd *cobra.Command, args []string) {
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+		resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes)
+		common.CheckForError(err)
+
+		built := 0
+		for _, element := range resolvedConfig.Images {
+			if element.Build == nil {
+				continue
+			}
+			if len(args) > 0 && !providesAny(element, args) {
+				continue
+			}
+
+			tag, err := image.EnsureBuilt(element.Name, buildSpec(element), true)
+			if err != nil {
+				log.Fatal().Err(err).Str("name", element.Name).Msg("failed to build image")
+			}
+			fmt.Printf("Built %s [%s].\n", element.Name, tag)
+			built++
+		}
+
+		if built == 0 {
+			fmt.Println("No commands with a build configuration found.")
+		}
+	},
+}
+
+// buildSpec resolves the build configuration of the entry relative to the configuration file it was defined in
+func buildSpec(element config.RunConfigurationEntry) image.BuildSpec {
+	context := element.Build.Context
+	if context == "" {
+		context = "."
+	}
+	if !filepath.IsAbs(context) {
+		context = filepath.Join(filepath.Dir(element.Source), context)
+	}
+
+	dockerfile := element.Build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(context, dockerfile)
+	}
+
+	return image.BuildSpec{Context: context, Dockerfile: dockerfile, Args: element.Build.Args}
+}
+
+// providesAny checks if the entry provides any of the commands
+func providesAny(element config.RunConfigurationEntry, commands []string) bool {
+	for _, command := range commands {
+		if isProvided, _ := collection.InArray(command, element.Provides); isProvided {
+			return true
+		}
+	}
+	return false
+}
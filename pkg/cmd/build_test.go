@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
+)
+
+func TestCommandImageBuild(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tools"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tools", "Dockerfile"), []byte("FROM alpine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	element := config.RunConfigurationEntry{Name: "tool", Source: filepath.Join(dir, ".envcli.yml"), Build: &config.BuildEntry{Context: "tools"}}
+
+	containerImage, err := commandImage(element, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, _ := image.BuildTag("tool", image.BuildSpec{Context: filepath.Join(dir, "tools"), Dockerfile: filepath.Join(dir, "tools", "Dockerfile")})
+	if containerImage != expected {
+		t.Errorf("expected the build tag %s relative to the configuration file, got %s", expected, containerImage)
+	}
+}
+
+func TestCommandImageMirror(t *testing.T) {
+	original := propConfig
+	propConfig = config.PropertyConfigurationFile{Properties: map[string]string{"registry-mirrors": "docker.io/=mirror.corp/dockerhub/"}}
+	t.Cleanup(func() { propConfig = original })
+
+	containerImage, err := commandImage(config.RunConfigurationEntry{Name: "node", Image: "node:18"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if containerImage != "mirror.corp/dockerhub/library/node:18" {
+		t.Errorf("expected the mirror rewrite to be applied, got %s", containerImage)
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/cache"
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().String("older-than", "30d", "Remove caches that haven't been used within this duration (ex. 30d, 2w, 12h)")
+	cacheCmd.AddCommand(cacheKeyCmd)
+	cacheKeyCmd.Flags().StringSlice("key-from", []string{}, "Files the cache key is computed from (ex. package-lock.json,go.sum)")
+	cacheCmd.AddCommand(cacheExportCmd)
+	cacheExportCmd.Flags().StringSlice("key-from", []string{}, "Files the cache key is computed from (ex. package-lock.json,go.sum)")
+	cacheExportCmd.Flags().StringP("output", "o", "", "Archive file, defaults to envcli-cache-<key>.tar.zst")
+	cacheExportCmd.Flags().StringArray("cache", []string{}, "Caches to export, defaults to the caches of the current configuration")
+	cacheCmd.AddCommand(cacheImportCmd)
+	cacheImportCmd.Flags().StringSlice("key-from", []string{}, "Files the cache key is computed from, the import fails if the key of the archive doesn't match")
+}
+
+var cacheCmd = &cobra.Command{
+	Use:     "cache",
+	Short:   "manages the caches and derived images created by envcli",
+	Aliases: []string{},
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+		os.Exit(0)
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists all caches with their size, last usage and the images that use them",
+	Run: func(cmd *cobra.Command, args []string) {
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+		cachePath := requireCachePath()
+
+		caches, err := cache.List(cachePath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to list caches")
+		}
+
+		// images of the current configuration that reference a cache
+		configuredImages := make(map[string][]string)
+		if resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes); err == nil {
+			for _, element := range resolvedConfig.Images {
+				for _, cachingEntry := range element.Caching {
+					configuredImages[cachingEntry.Name] = append(configuredImages[cachingEntry.Name], element.Image)
+				}
+			}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCACHE\tSCOPE\tTYPE\tSIZE\tLAST USED\tIMAGES")
+		for _, info := range caches {
+			images := info.Images
+			for _, configuredImage := range configuredImages[info.Cache] {
+				if isKnown, _ := collection.InArray(configuredImage, images); !isKnown && configuredImage != "" {
+					images = append(images, configuredImage)
+				}
+			}
+			sort.Strings(images)
+
+			size := "-"
+			if info.Size >= 0 {
+				size = cache.FormatSize(info.Size)
+			}
+			lastUsed := "unknown"
+			if !info.LastUsed.IsZero() {
+				lastUsed = info.LastUsed.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", info.Name, info.Cache, info.Scope, info.Type, size, lastUsed, strings.Join(images, ", "))
+		}
+		_ = w.Flush()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "removes the specified caches",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			log.Fatal().Msg("Please provide the names of the caches you want to clear. [envcli cache clear name...]")
+		}
+		cachePath := requireCachePath()
+
+		for _, name := range args {
+			if err := cache.Clear(cachePath, name); err != nil {
+				log.Fatal().Err(err).Str("cache", name).Msg("failed to clear cache")
+			}
+			fmt.Printf("Cleared cache %s.\n", name)
+		}
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "removes unused caches and derived images with baked before_scripts, they will be recreated on next use",
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThanValue, _ := cmd.Flags().GetString("older-than")
+		olderThan, err := cache.ParseAge(olderThanValue)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid value for --older-than")
+		}
+
+		// caches
+		if cachePath, cacheEnabled := cache.GetCachePath(propConfig.Properties); cacheEnabled {
+			removedCaches, err := cache.Prune(cachePath, olderThan)
+			for _, name := range removedCaches {
+				fmt.Printf("Removed cache %s.\n", name)
+			}
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to prune caches")
+			}
+			fmt.Printf("Removed %d caches.\n", len(removedCaches))
+		}
+
+		// derived images
+		if _, err := common.DetectContainerRuntime(); err != nil {
+			log.Warn().Err(err).Msg("skipping removal of baked images")
+			return
+		}
+		removed, err := image.RemoveBaked()
+		for _, removedImage := range removed {
+			fmt.Printf("Removed image %s.\n", removedImage)
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to remove baked images")
+		}
+		fmt.Printf("Removed %d baked images.\n", len(removed))
+	},
+}
+
+var cacheKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "prints the cache key computed from the content of the specified files",
+	Run: func(cmd *cobra.Command, args []string) {
+		keyFrom, _ := cmd.Flags().GetStringSlice("key-from")
+
+		key, err := cache.ComputeKey(config.GetProjectOrWorkingDirectory(), keyFrom)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to compute cache key")
+		}
+		fmt.Println(key)
+	},
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "exports directory caches into a archive, to store them as ci cache or artifact",
+	Run: func(cmd *cobra.Command, args []string) {
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+		keyFrom, _ := cmd.Flags().GetStringSlice("key-from")
+		output, _ := cmd.Flags().GetString("output")
+		names, _ := cmd.Flags().GetStringArray("cache")
+		cachePath := requireCachePath()
+
+		key, err := cache.ComputeKey(config.GetProjectOrWorkingDirectory(), keyFrom)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to compute cache key")
+		}
+		if output == "" {
+			output = "envcli-cache-" + key[:16] + ".tar.zst"
+		}
+
+		// caches of the current configuration
+		if len(names) == 0 {
+			resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load configuration, specify the caches with --cache")
+			}
+			for _, element := range resolvedConfig.Images {
+				for _, cachingEntry := range element.Caching {
+					names = append(names, cachingEntry.Name)
+				}
+			}
+		}
+
+		caches, err := cache.List(cachePath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to list caches")
+		}
+		projectDirectory := config.GetProjectOrWorkingDirectory()
+		var selected []cache.Info
+		for _, info := range caches {
+			if isSelected, _ := collection.InArray(info.Name, names); !isSelected {
+				if isSelected, _ = collection.InArray(info.Cache, names); !isSelected || (info.Scope == cache.ScopeProject && info.Project != projectDirectory) {
+					continue
+				}
+			}
+			if info.Type != cache.TypeDirectory {
+				log.Warn().Str("cache", info.Name).Msg("skipping cache, only directory caches can be exported")
+				continue
+			}
+			selected = append(selected, info)
+		}
+		if len(selected) == 0 {
+			log.Fatal().Msg("no caches to export")
+		}
+
+		file, err := os.Create(output)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to create archive")
+		}
+		defer file.Close()
+		manifest, err := cache.Export(selected, key, file)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to export caches")
+		}
+
+		for _, entry := range manifest.Caches {
+			fmt.Printf("Exported cache %s.\n", entry.Name)
+		}
+		fmt.Printf("Exported %d caches with key %s to %s.\n", len(manifest.Caches), key, output)
+	},
+}
+
+var cacheImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "imports the caches of a archive created by cache export, existing caches are replaced",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal().Msg("Please provide the archive you want to import. [envcli cache import cache.tar.zst]")
+		}
+		keyFrom, _ := cmd.Flags().GetStringSlice("key-from")
+		cachePath := requireCachePath()
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to open archive")
+		}
+		defer file.Close()
+
+		var expectedKey string
+		if len(keyFrom) > 0 {
+			if expectedKey, err = cache.ComputeKey(config.GetProjectOrWorkingDirectory(), keyFrom); err != nil {
+				log.Fatal().Err(err).Msg("failed to compute cache key")
+			}
+		}
+
+		manifest, err := cache.Import(cachePath, file, expectedKey, config.GetProjectOrWorkingDirectory())
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to import caches")
+		}
+		for _, entry := range manifest.Caches {
+			fmt.Printf("Imported cache %s.\n", entry.Name)
+		}
+		fmt.Printf("Imported %d caches with key %s.\n", len(manifest.Caches), manifest.Key)
+	},
+}
+
+// requireCachePath returns the cache root or exits if caching is disabled
+func requireCachePath() string {
+	cachePath, cacheEnabled := cache.GetCachePath(propConfig.Properties)
+	if !cacheEnabled {
+		log.Fatal().Msg("Cache is disabled, cache-path is set to off.")
+	}
+
+	return cachePath
+}
+
+// evictCaches applies the cache-max-size property by removing the least recently used caches
+func evictCaches(cachePath string, usedCaches []cache.Usage) {
+	maxSizeValue := collection.MapGetValueOrDefault(propConfig.Properties, "cache-max-size", "")
+	if maxSizeValue == "" {
+		return
+	}
+
+	maxSize, err := cache.ParseSize(maxSizeValue)
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid cache-max-size property, skipping cache eviction")
+		return
+	}
+
+	var keep []string
+	for _, usage := range usedCaches {
+		keep = append(keep, usage.Key)
+	}
+
+	evicted, err := cache.Evict(cachePath, maxSize, keep)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to evict caches")
+	}
+	for _, name := range evicted {
+		log.Info().Str("cache", name).Msg("evicted least recently used cache, cache-max-size exceeded")
+	}
+}
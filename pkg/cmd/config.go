@@ -3,8 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +17,7 @@ func init() {
 	configCmd.AddCommand(getCmd)
 	configCmd.AddCommand(getAllCmd)
 	configCmd.AddCommand(unsetCmd)
+	configCmd.AddCommand(explainCmd)
 }
 
 var configCmd = &cobra.Command{
@@ -81,3 +84,36 @@ var unsetCmd = &cobra.Command{
 		fmt.Printf("Value of variable %s set to [].\n", varName)
 	},
 }
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "explains which configuration is used for a command",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check Parameters
+		if len(args) != 1 {
+			log.Fatal().Msg("Please provide the command you want to explain. [envcli config explain command]")
+		}
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+		// Resolve
+		commandConfig, err := config.GetCommandConfiguration(args[0], filesystem.GetWorkingDirectory(), configIncludes)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load command config")
+		}
+		containerImage, err := commandImage(commandConfig, false)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to resolve container image")
+		}
+
+		fmt.Printf("Command:        %s\n", args[0])
+		fmt.Printf("Name:           %s\n", commandConfig.Name)
+		fmt.Printf("Scope:          %s\n", commandConfig.Scope)
+		if commandConfig.Build != nil {
+			fmt.Printf("Build:          %s\n", buildSpec(commandConfig).Context)
+		} else {
+			fmt.Printf("Image:          %s\n", commandConfig.Image)
+		}
+		fmt.Printf("Resolved Image: %s\n", containerImage)
+		fmt.Printf("Provides:       %s\n", strings.Join(commandConfig.Provides, ", "))
+	},
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/scaffold"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolP("yes", "y", false, "Adds all proposed images without asking for confirmation")
+	initCmd.Flags().BoolP("force", "f", false, "Overwrites an existing .envcli.yml")
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "creates a .envcli.yml with images for the project types detected in the current directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+		force, _ := cmd.Flags().GetBool("force")
+
+		dir := filesystem.GetWorkingDirectory()
+		configFile := filepath.Join(dir, ".envcli.yml")
+		if _, err := os.Stat(configFile); err == nil && !force {
+			log.Fatal().Str("file", configFile).Msg("configuration file already exists, use --force to overwrite it")
+		}
+
+		detections := scaffold.Detect(dir, loadCatalog())
+		if len(detections) == 0 {
+			log.Error().Msg("no known project type detected, please take a look at the examples to create the .envcli.yml")
+			os.Exit(1)
+		}
+
+		// confirmation
+		var entries []config.RunConfigurationEntry
+		reader := bufio.NewReader(os.Stdin)
+		for _, detection := range detections {
+			fmt.Printf("Detected %s: %s (%s)\n", detection.Marker, detection.Entry.Name, detection.Entry.Image)
+			if !yes {
+				confirmed, err := confirm(reader, "Add "+detection.Entry.Name+"?")
+				if err != nil {
+					log.Fatal().Err(err).Msg("failed to read the confirmation, use --yes to add all proposed images")
+				}
+				if !confirmed {
+					continue
+				}
+			}
+			entries = append(entries, detection.Entry)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No images selected, nothing to do.")
+			return
+		}
+
+		if err := os.WriteFile(configFile, []byte(scaffold.RenderConfiguration(entries)), 0644); err != nil {
+			log.Fatal().Err(err).Str("file", configFile).Msg("failed to write configuration file")
+		}
+		fmt.Printf("Created %s with %d images.\n", configFile, len(entries))
+	},
+}
+
+// confirm asks a yes/no question, yes is the default
+func confirm(reader *bufio.Reader, question string) (bool, error) {
+	for {
+		fmt.Printf("%s [Y/n] ", question)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && answer != "") {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/EnvCLI/EnvCLI/pkg/aliases"
@@ -14,6 +16,8 @@ import (
 func init() {
 	rootCmd.AddCommand(installAliasesCmd)
 	installAliasesCmd.Flags().StringP("scope", "s", "all", "Install aliases for the specified scope (project, global or all)")
+	installAliasesCmd.Flags().String("mode", aliases.ModeScript, "Kind of alias - allowed: script, symlink (to the envcli binary), hardlink (to the envcli binary)")
+	installAliasesCmd.Flags().String("on-conflict", "overwrite", "What to do if a alias shadows a host binary - allowed: skip, overwrite (with a warning), fail")
 }
 
 var installAliasesCmd = &cobra.Command{
@@ -22,47 +26,154 @@ var installAliasesCmd = &cobra.Command{
 	Aliases: []string{},
 	Run: func(cmd *cobra.Command, args []string) {
 		scopeFilter, _ := cmd.Flags().GetString("scope")
-		log.Debug().Msg("Installing aliases ...")
+		mode, _ := cmd.Flags().GetString("mode")
+		requireAliasMode(mode)
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		aliasPath := aliases.GetAliasPath(propConfig.Properties)
+		log.Debug().Str("dir", aliasPath).Msg("Installing aliases ...")
+		if err := os.MkdirAll(aliasPath, 0755); err != nil {
+			log.Fatal().Err(err).Str("dir", aliasPath).Msg("failed to create alias directory")
+		}
 
-		// create global-scoped aliases
-		if scopeFilter == "all" || scopeFilter == "global" {
-			var globalConfigPath = collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-path", filesystem.GetExecutionDirectory())
-			log.Debug().Msg("Will load the global configuration from [" + globalConfigPath + "].")
-			globalConfig, _ := config.LoadProjectConfig(globalConfigPath + "/.envcli.yml")
+		configured, err := configuredAliases(aliasPath, scopeFilter, mode)
+		if err != nil {
+			log.Error().Err(err).Msg("Can't install aliases, no valid configuration was found!")
+			os.Exit(1)
+		}
+		configured = resolveAliasConflicts(aliasPath, configured, onConflict)
 
-			for _, element := range globalConfig.Images {
-				element.Scope = "Global"
-				log.Debug().Msg("Created aliases for " + element.Name + " [Scope: " + element.Scope + "]")
+		manifest, err := aliases.LoadManifest(aliasPath)
+		if err != nil {
+			log.Warn().Err(err).Msg("alias manifest is corrupt, recreating it")
+		}
+		installed := installAliases(aliasPath, configured, &manifest)
+		if err := aliases.SaveManifest(aliasPath, manifest); err != nil {
+			log.Fatal().Err(err).Msg("failed to save alias manifest")
+		}
 
-				// for each provided command
-				for _, currentCommand := range element.Provides {
-					aliases.InstallAlias(currentCommand, element.Scope)
-				}
+		fmt.Printf("Installed %d aliases into %s.\n", installed, aliasPath)
+		if !aliases.IsOnPath(aliasPath) {
+			rcFile, line := aliases.PathHint(aliasPath)
+			if rcFile != "" {
+				fmt.Printf("%s is not on your PATH, add the following line to %s:\n", aliasPath, rcFile)
+			} else {
+				fmt.Printf("%s is not on your PATH, run the following command in PowerShell and restart your terminal:\n", aliasPath)
 			}
+			fmt.Printf("  %s\n", line)
 		}
+	},
+}
 
-		// create project-scoped aliases
-		if scopeFilter == "all" || scopeFilter == "project" {
-			var projectDirectory, projectDirectoryErr = config.GetProjectDirectory()
-			if projectDirectoryErr != nil && scopeFilter == "project" {
-				log.Error().Msg("Can't install project-specific aliases as no valid project was found!")
-				os.Exit(1)
-			} else if projectDirectoryErr != nil {
-				log.Warn().Msg("Can't find a project directory, not throwing a error since all aliases are supposed to be installed!")
-			} else {
-				log.Debug().Msg("Project Directory: " + projectDirectory)
-				projectConfig, _ := config.LoadProjectConfig(projectDirectory + "/.envcli.yml")
+// configuredAliases returns the aliases for all commands provided by the global and / or project configuration, project commands take precedence
+func configuredAliases(aliasPath string, scopeFilter string, mode string) ([]aliases.Alias, error) {
+	var configured []aliases.Alias
 
-				for _, element := range projectConfig.Images {
-					element.Scope = "Project"
-					log.Debug().Msg("Created aliases for " + element.Name + " [Scope: " + element.Scope + "]")
+	// global-scoped aliases
+	if scopeFilter == "all" || scopeFilter == "global" {
+		var globalConfigPath = collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-path", filesystem.GetExecutionDirectory())
+		log.Debug().Msg("Will load the global configuration from [" + globalConfigPath + "].")
+		globalConfig, err := config.LoadProjectConfig(globalConfigPath + "/.envcli.yml")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		for _, element := range globalConfig.Images {
+			if element.Alias == "false" {
+				continue
+			}
+			for _, currentCommand := range element.Provides {
+				configured = append(configured, aliases.Alias{Command: currentCommand, Scope: "Global", Mode: mode, Version: Version, Path: aliases.AliasFile(aliasPath, currentCommand, mode), Force: element.Alias == "force"})
+			}
+		}
+	}
 
-					// for each provided command
-					for _, currentCommand := range element.Provides {
-						aliases.InstallAlias(currentCommand, element.Scope)
-					}
+	// project-scoped aliases
+	if scopeFilter == "all" || scopeFilter == "project" {
+		var projectDirectory, projectDirectoryErr = config.GetProjectDirectory()
+		if projectDirectoryErr != nil && scopeFilter == "project" {
+			return nil, errors.New("no project directory found")
+		} else if projectDirectoryErr != nil {
+			log.Warn().Msg("Can't find a project directory, not throwing a error since all aliases are supposed to be installed!")
+		} else {
+			log.Debug().Msg("Project Directory: " + projectDirectory)
+			projectConfig, err := config.LoadProjectConfig(projectDirectory + "/.envcli.yml")
+			if err != nil {
+				return nil, err
+			}
+
+			for _, element := range projectConfig.Images {
+				if element.Alias == "false" {
+					continue
+				}
+				for _, currentCommand := range element.Provides {
+					configured = append(configured, aliases.Alias{Command: currentCommand, Scope: "Project", Project: projectDirectory, Mode: mode, Version: Version, Path: aliases.AliasFile(aliasPath, currentCommand, mode), Force: element.Alias == "force"})
 				}
 			}
 		}
-	},
+	}
+
+	return configured, nil
+}
+
+// requireAliasMode exits if the alias mode is invalid
+func requireAliasMode(mode string) {
+	if mode != aliases.ModeScript && mode != aliases.ModeSymlink && mode != aliases.ModeHardlink {
+		log.Fatal().Str("mode", mode).Msg("invalid alias mode, allowed: script, symlink, hardlink")
+	}
+}
+
+// resolveAliasConflicts reports aliases that shadow host binaries and applies the conflict strategy, returns the aliases that should be installed
+func resolveAliasConflicts(aliasPath string, configured []aliases.Alias, onConflict string) []aliases.Alias {
+	if onConflict != "skip" && onConflict != "overwrite" && onConflict != "fail" {
+		log.Fatal().Str("on-conflict", onConflict).Msg("invalid conflict strategy, allowed: skip, overwrite, fail")
+	}
+
+	var resolved []aliases.Alias
+	conflicts := 0
+	for _, alias := range configured {
+		hostBinary, found := aliases.FindHostBinary(alias.Command, aliasPath)
+		if !found || alias.Force {
+			resolved = append(resolved, alias)
+			continue
+		}
+
+		conflicts++
+		switch onConflict {
+		case "skip":
+			log.Warn().Str("command", alias.Command).Str("host", hostBinary).Msg("skipping alias, it would shadow a host binary")
+		case "overwrite":
+			log.Warn().Str("command", alias.Command).Str("host", hostBinary).Msg("alias shadows a host binary, depending on the PATH order")
+			resolved = append(resolved, alias)
+		case "fail":
+			log.Error().Str("command", alias.Command).Str("host", hostBinary).Msg("alias would shadow a host binary")
+		}
+	}
+
+	if onConflict == "fail" && conflicts > 0 {
+		log.Error().Int("conflicts", conflicts).Msg("no aliases installed, use --on-conflict or alias: force / false to resolve the conflicts")
+		os.Exit(1)
+	}
+
+	return resolved
+}
+
+// installAliases installs the aliases and records them in the manifest, returns the number of installed aliases
+func installAliases(aliasPath string, configured []aliases.Alias, manifest *aliases.Manifest) int {
+	installed := 0
+	for _, alias := range configured {
+		// the file name depends on the mode on windows
+		if existingAlias, ok := manifest.Get(alias.Command); ok && existingAlias.Path != alias.Path {
+			_ = aliases.Uninstall(existingAlias)
+		}
+
+		if err := aliases.InstallAlias(aliasPath, alias.Command, alias.Scope, alias.Mode); err != nil {
+			log.Error().Err(err).Str("command", alias.Command).Msg("Failed to install alias!")
+			continue
+		}
+		log.Debug().Msg("Created alias for " + alias.Command + " [Scope: " + alias.Scope + "]")
+		manifest.Add(alias)
+		installed++
+	}
+
+	return installed
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringP("output", "o", "table", "Output format - allowed: table, json, yaml")
+}
+
+// commandListEntry is a command provided by the resolved configuration
+type commandListEntry struct {
+	Command     string `json:"command" yaml:"command"`
+	Name        string `json:"name" yaml:"name"`
+	Image       string `json:"image" yaml:"image"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Scope       string `json:"scope" yaml:"scope"`
+	Source      string `json:"source" yaml:"source"`
+	ShadowedBy  string `json:"shadowedBy,omitempty" yaml:"shadowedBy,omitempty"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists all commands available in the current directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+		if output != "table" && output != "json" && output != "yaml" {
+			log.Fatal().Str("output", output).Msg("invalid output format, allowed: table, json, yaml")
+		}
+
+		resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load the configuration")
+		}
+		entries := listCommands(resolvedConfig)
+
+		switch output {
+		case "json":
+			content, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to render the command list")
+			}
+			fmt.Println(string(content))
+		case "yaml":
+			content, err := yaml.Marshal(entries)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to render the command list")
+			}
+			fmt.Print(string(content))
+		default:
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "COMMAND\tIMAGE\tSCOPE\tSOURCE\tDESCRIPTION")
+			for _, entry := range entries {
+				scope := entry.Scope
+				if entry.ShadowedBy != "" {
+					scope += " (shadowed by " + entry.ShadowedBy + ")"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Command, entry.Image, scope, entry.Source, entry.Description)
+			}
+			_ = w.Flush()
+		}
+	},
+}
+
+// listCommands returns a entry for each provided command, commands that are provided by a entry with a higher precedence are marked as shadowed
+func listCommands(resolvedConfig config.ConfigurationFile) []commandListEntry {
+	entries := []commandListEntry{}
+	providedBy := make(map[string]string)
+
+	for _, element := range resolvedConfig.Images {
+		image := element.Image
+		if element.Build != nil {
+			image = "build: " + buildSpec(element).Context
+		}
+
+		for _, command := range element.Provides {
+			entry := commandListEntry{Command: command, Name: element.Name, Image: image, Description: element.Description, Scope: element.Scope, Source: element.Source}
+			if scope, ok := providedBy[command]; ok {
+				entry.ShadowedBy = scope
+			} else {
+				providedBy[command] = element.Scope
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
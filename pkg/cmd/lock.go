@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.Flags().Bool("check", false, "Fails if the lock file does not match the images of the project configuration")
+}
+
+var lockCmd = &cobra.Command{
+	Use:     "lock",
+	Short:   "pins the images of the project configuration to their digests in " + config.LockFileName,
+	Aliases: []string{},
+	Run: func(cmd *cobra.Command, args []string) {
+		check, _ := cmd.Flags().GetBool("check")
+
+		projectDirectory, err := config.GetProjectDirectory()
+		if err != nil {
+			log.Fatal().Err(err).Msg("can't lock images, no project configuration found")
+		}
+		projectConfig, err := config.LoadProjectConfig(projectDirectory + "/.envcli.yml")
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load project configuration")
+		}
+		lockFile := projectDirectory + "/" + config.LockFileName
+
+		var images []string
+		for _, element := range projectConfig.Images {
+			images = append(images, element.Image)
+		}
+		images = image.Unique(images)
+
+		// check
+		if check {
+			lock, err := config.LoadLockFile(lockFile)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load lock file, run `envcli lock` to create it")
+			}
+
+			missing, unused := lock.Diff(images)
+			for _, image := range missing {
+				fmt.Printf("not locked: %s\n", image)
+			}
+			for _, image := range unused {
+				fmt.Printf("no longer used: %s\n", image)
+			}
+			if len(missing) > 0 || len(unused) > 0 {
+				log.Error().Msg("lock file is stale, run `envcli lock` to update it")
+				os.Exit(1)
+			}
+
+			fmt.Printf("%s is up to date.\n", config.LockFileName)
+			return
+		}
+
+		// resolve digests, through the registry mirror if one is configured
+		client := registry.NewClient()
+		lock := config.LockFile{}
+		for _, image := range images {
+			digest, err := client.Digest(mirrorImage(image))
+			if err != nil {
+				log.Fatal().Err(err).Str("image", image).Msg("failed to resolve image digest")
+			}
+
+			fmt.Printf("%s -> %s\n", image, digest)
+			lock.Images = append(lock.Images, config.LockEntry{Image: image, Digest: digest})
+		}
+
+		err = config.SaveLockFile(lockFile, lock)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to save lock file")
+		}
+		fmt.Printf("Locked %d images in %s.\n", len(lock.Images), lockFile)
+	},
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+	"github.com/EnvCLI/EnvCLI/pkg/scaffold"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(outdatedCmd)
+	outdatedCmd.Flags().Bool("write", false, "Updates the images in the project configuration to the latest tags")
+}
+
+// outdatedImage is a configured image with a newer tag that follows the same tag pattern
+type outdatedImage struct {
+	Image  string
+	Tag    string
+	Latest string
+}
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "checks the images of the project configuration for newer tags",
+	Run: func(cmd *cobra.Command, args []string) {
+		write, _ := cmd.Flags().GetBool("write")
+
+		projectDirectory, err := config.GetProjectDirectory()
+		if err != nil {
+			log.Fatal().Err(err).Msg("can't check images, no project configuration found")
+		}
+		configFile := projectDirectory + "/.envcli.yml"
+		projectConfig, err := config.LoadProjectConfig(configFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load project configuration")
+		}
+
+		var images []string
+		for _, element := range projectConfig.Images {
+			if element.Build == nil && element.Image != "" {
+				images = append(images, element.Image)
+			}
+		}
+
+		outdated := findOutdatedImages(registry.NewClient(), image.Unique(images))
+		if len(outdated) == 0 {
+			fmt.Println("All images are up to date.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "IMAGE\tCURRENT\tLATEST")
+		for _, entry := range outdated {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Image, entry.Tag, entry.Latest)
+		}
+		_ = w.Flush()
+
+		if !write {
+			return
+		}
+
+		// update the configuration, the existing content including comments is kept as-is
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to read project configuration")
+		}
+		updated := string(content)
+		for _, entry := range outdated {
+			var replaced int
+			updated, replaced = scaffold.ReplaceImage(updated, entry.Image, registry.ReplaceTag(entry.Image, entry.Latest))
+			if replaced == 0 {
+				log.Warn().Str("image", entry.Image).Msg("image not found in the project configuration, please update it manually")
+			}
+		}
+		if err := os.WriteFile(configFile, []byte(updated), 0644); err != nil {
+			log.Fatal().Err(err).Msg("failed to write project configuration")
+		}
+		fmt.Printf("Updated %s.\n", configFile)
+
+		if _, err := os.Stat(projectDirectory + "/" + config.LockFileName); err == nil {
+			fmt.Printf("Run `envcli lock` to update %s.\n", config.LockFileName)
+		}
+	},
+}
+
+// findOutdatedImages queries the tags of the images and returns the images with a newer tag of the same tag pattern
+func findOutdatedImages(client *registry.Client, images []string) []outdatedImage {
+	var outdated []outdatedImage
+	for _, imageReference := range images {
+		ref, err := registry.ParseReference(imageReference)
+		if err != nil {
+			log.Warn().Err(err).Str("image", imageReference).Msg("invalid image reference")
+			continue
+		}
+		if ref.Digest != "" || registry.ReplaceTag(imageReference, ref.Tag) != imageReference {
+			log.Debug().Str("image", imageReference).Msg("skipping image without explicit tag or pinned to a digest")
+			continue
+		}
+
+		// the tags are listed through the registry mirror if one is configured, the configuration keeps the original reference
+		tags, err := client.Tags(mirrorImage(imageReference))
+		if err != nil {
+			log.Warn().Err(err).Str("image", imageReference).Msg("failed to list image tags")
+			continue
+		}
+		if newer := registry.NewerTags(ref.Tag, tags); len(newer) > 0 {
+			outdated = append(outdated, outdatedImage{Image: imageReference, Tag: ref.Tag, Latest: newer[0]})
+		}
+	}
+
+	return outdated
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+)
+
+func TestFindOutdatedImagesMirror(t *testing.T) {
+	var requested []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path != "/v2/dockerhub/library/node/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"tags": {"16", "18", "20", "20-alpine"}})
+	}))
+	t.Cleanup(server.Close)
+
+	original := propConfig
+	propConfig = config.PropertyConfigurationFile{Properties: map[string]string{"registry-mirrors": "docker.io/=" + strings.TrimPrefix(server.URL, "https://") + "/dockerhub/"}}
+	t.Cleanup(func() { propConfig = original })
+
+	client := registry.NewClient()
+	client.HTTPClient = server.Client()
+	outdated := findOutdatedImages(client, []string{"node:18"})
+
+	if len(outdated) != 1 || outdated[0].Image != "node:18" || outdated[0].Latest != "20" {
+		t.Errorf("expected the tags to be listed through the mirror, got %+v (requests %v)", outdated, requested)
+	}
+}
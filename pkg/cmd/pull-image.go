@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/EnvCLI/EnvCLI/pkg/common"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
-	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -14,6 +17,9 @@ import (
 
 func init() {
 	rootCmd.AddCommand(pullImageCmd)
+	pullImageCmd.Flags().Bool("all", false, "Pull the images of all commands in the resolved configuration")
+	pullImageCmd.Flags().StringP("scope", "s", "all", "Only pull images of the specified scope when using --all (project, include, global or all)")
+	pullImageCmd.Flags().IntP("parallel", "j", 4, "Maximum number of images that are pulled at the same time")
 }
 
 var pullImageCmd = &cobra.Command{
@@ -21,21 +27,79 @@ var pullImageCmd = &cobra.Command{
 	Short:   "pulls the needed images for the specified commands",
 	Aliases: []string{},
 	Run: func(cmd *cobra.Command, args []string) {
-		configIncludes, _ := cmd.PersistentFlags().GetStringArray("config-include")
-		fmt.Printf("Pulling images for [%s].\n", strings.Join(args, ", "))
+		all, _ := cmd.Flags().GetBool("all")
+		scopeFilter, _ := cmd.Flags().GetString("scope")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
 
-		for _, cmd := range args {
-			log.Debug().Msg("Pulling image for command [" + cmd + "].")
+		// collect images
+		var images []string
+		if all {
+			if scopeFilter != "all" && scopeFilter != "project" && scopeFilter != "include" && scopeFilter != "global" {
+				log.Fatal().Str("scope", scopeFilter).Msg("invalid scope, allowed: project, include, global or all")
+			}
 
-			// config: try to load command configuration
-			commandConfig, err := config.GetCommandConfiguration(cmd, filesystem.GetWorkingDirectory(), configIncludes)
+			resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes)
 			common.CheckForError(err)
 
-			// container
-			containerRuntime := &containerruntime.ContainerRuntime{}
-			container := containerRuntime.NewContainer()
-			container.SetImage(commandConfig.Image)
-			container.PullImage()
+			for _, element := range resolvedConfig.Images {
+				if element.Build != nil {
+					continue
+				}
+				if scopeFilter == "all" || strings.EqualFold(scopeFilter, element.Scope) {
+					images = append(images, resolveImage(element.Image))
+				}
+			}
+		} else {
+			if len(args) == 0 {
+				log.Fatal().Msg("Please provide the commands you want to pull the images for or use --all. [envcli pull-image command...]")
+			}
+
+			for _, cmd := range args {
+				log.Debug().Msg("Pulling image for command [" + cmd + "].")
+
+				// config: try to load command configuration
+				commandConfig, err := config.GetCommandConfiguration(cmd, filesystem.GetWorkingDirectory(), configIncludes)
+				common.CheckForError(err)
+				if commandConfig.Build != nil {
+					log.Info().Msg("Skipping command [" + cmd + "], the image is built locally.")
+					continue
+				}
+
+				images = append(images, resolveImage(commandConfig.Image))
+			}
+		}
+		images = image.Unique(images)
+		fmt.Printf("Pulling %d images [%s].\n", len(images), strings.Join(images, ", "))
+
+		// pull
+		finished := 0
+		results := image.PullAll(images, parallel, image.RuntimePull, func(result image.PullResult) {
+			finished++
+			if result.Err != nil {
+				fmt.Printf("[%d/%d] failed %s: %s\n", finished, len(images), result.Image, result.Err.Error())
+			} else {
+				fmt.Printf("[%d/%d] pulled %s (%s)\n", finished, len(images), result.Image, result.Duration.Round(time.Millisecond))
+			}
+		})
+
+		// summary
+		failed := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nIMAGE\tSTATUS\tDURATION")
+		for _, result := range results {
+			status := "ok"
+			if result.Err != nil {
+				status = "failed"
+				failed++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", result.Image, status, result.Duration.Round(time.Millisecond))
+		}
+		_ = w.Flush()
+
+		if failed > 0 {
+			log.Error().Int("failed", failed).Int("total", len(results)).Msg("failed to pull images")
+			os.Exit(1)
 		}
 	},
 }
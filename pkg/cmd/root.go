@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/aliases"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
 	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/mattn/go-colorable"
@@ -85,7 +87,8 @@ var rootCmd = &cobra.Command{
 		log.Debug().Str("log-level", cfg.LogLevel).Str("log-format", cfg.LogFormat).Bool("log-caller", cfg.LogCaller).Msg("configured logging")
 
 		// Global Configuration
-		propConfig, propConfigErr := config.LoadPropertyConfig()
+		var propConfigErr error
+		propConfig, propConfigErr = config.LoadPropertyConfig()
 
 		// Configure Proxy Server
 		if propConfigErr == nil {
@@ -93,6 +96,12 @@ var rootCmd = &cobra.Command{
 			os.Setenv("HTTP_PROXY", collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""))
 			os.Setenv("HTTPS_PROXY", collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""))
 		}
+
+		// Update Check, once a day (not in CI)
+		startUpdateCheck(cmd)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		finishUpdateCheck()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
@@ -100,7 +109,26 @@ var rootCmd = &cobra.Command{
 	},
 }
 
-// Execute executes the root command.
+// Execute executes the root command, invocations through a symlink or hardlink alias are dispatched to run
 func Execute() error {
+	if command, ok := linkedCommand(); ok {
+		rootCmd.SetArgs(append([]string{"run", command}, os.Args[1:]...))
+	}
+
 	return rootCmd.Execute()
 }
+
+// linkedCommand returns the command if envcli was invoked through a alias link instead of its own name
+func linkedCommand() (string, bool) {
+	if strings.HasPrefix(strings.ToLower(filepath.Base(os.Args[0])), "envcli") {
+		return "", false
+	}
+
+	properties, _ := config.LoadPropertyConfig()
+	manifest, err := aliases.LoadManifest(aliases.GetAliasPath(properties.Properties))
+	if err != nil {
+		return "", false
+	}
+
+	return aliases.LinkedCommand(manifest, os.Args[0])
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/cache"
 	"github.com/EnvCLI/EnvCLI/pkg/common"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/image"
 	"github.com/cidverse/cidverseutils/pkg/cihelper"
 	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/cidverse/cidverseutils/pkg/containerruntime"
@@ -15,9 +19,12 @@ import (
 
 func init() {
 	rootCmd.AddCommand(runCmd)
-	installAliasesCmd.Flags().StringArrayP("env", "e", []string{}, "Sets environment variables within the containers")
-	installAliasesCmd.Flags().StringArrayP("port", "p", []string{}, "Publish ports of the container")
-	installAliasesCmd.Flags().StringArray("userArgs", []string{}, "Allows to specify custom arguments that will be passed to the docker run command for special cases")
+	runCmd.Flags().StringArrayP("env", "e", []string{}, "Sets environment variables within the containers")
+	runCmd.Flags().StringArrayP("port", "p", []string{}, "Publish ports of the container")
+	runCmd.Flags().StringArray("userArgs", []string{}, "Allows to specify custom arguments that will be passed to the docker run command for special cases")
+	runCmd.Flags().Bool("dry-run", false, "Prints the container runtime command instead of executing it")
+	// flags after the command name belong to the command
+	runCmd.Flags().SetInterspersed(false)
 }
 
 var runCmd = &cobra.Command{
@@ -28,27 +35,31 @@ var runCmd = &cobra.Command{
 		env, _ := cmd.Flags().GetStringArray("env")
 		port, _ := cmd.Flags().GetStringArray("port")
 		userArgs, _ := cmd.Flags().GetStringArray("userArgs")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		configIncludes, _ := cmd.PersistentFlags().GetStringArray("config-include")
 
 		// parse command
 		commandName := args[0]
 
-		// iterate and quote args if needed
-		commandArgs := append([]string{commandName}, strings.Join(args[1:], " "))
-		commandWithArguments := common.ParseAndEscapeArgs(commandArgs)
-
-		log.Debug().Msg("Received request to run command [" + commandName + "] - with Arguments [" + commandWithArguments + "].")
-
 		// config: try to load command configuration
 		commandConfig, commandConfigErr := config.GetCommandConfiguration(commandName, filesystem.GetWorkingDirectory(), configIncludes)
 		if commandConfigErr != nil {
 			log.Fatal().Err(commandConfigErr).Msg("failed to load command config")
 		}
 
+		shellWrapped := commandConfig.Shell == "sh" || commandConfig.Shell == "bash"
+		commandWithArguments := commandArguments(args, shellWrapped)
+
+		log.Debug().Msg("Received request to run command [" + commandName + "] - with Arguments [" + commandWithArguments + "].")
+
 		// container runtime
 		containerRuntime := &containerruntime.ContainerRuntime{}
 		container := containerRuntime.NewContainer()
-		container.SetImage(commandConfig.Image)
+		containerImage, err := commandImage(commandConfig, !dryRun)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to resolve container image")
+		}
+		container.SetImage(containerImage)
 		container.SetEntrypoint(commandConfig.Entrypoint)
 		container.SetCommandShell(commandConfig.Shell)
 
@@ -76,12 +87,42 @@ var runCmd = &cobra.Command{
 		// feature: before_script
 		var commandWithBeforeScript = ""
 		commandWithBeforeScript = strings.TrimSpace(commandWithArguments)
-		if commandConfig.BeforeScript != nil {
+		if commandConfig.BeforeScript != nil && commandConfig.Bake {
+			// bake the before_script into a derived image, instead of running it on every invocation - the proxy is only passed as build arg to keep it out of the image
+			var beforeScript []string
+			for _, line := range commandConfig.BeforeScript {
+				line = strings.Replace(line, "{HTTPProxy}", "${http_proxy}", -1)
+				line = strings.Replace(line, "{HTTPSProxy}", "${https_proxy}", -1)
+				beforeScript = append(beforeScript, line)
+			}
+
+			if dryRun {
+				containerImage = image.BakeTag(commandConfig.Name, containerImage, beforeScript)
+			} else {
+				var bakeEnv []string
+				if httpProxy := collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""); httpProxy != "" {
+					bakeEnv = append(bakeEnv, "http_proxy="+httpProxy)
+				}
+				if httpsProxy := collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""); httpsProxy != "" {
+					bakeEnv = append(bakeEnv, "https_proxy="+httpsProxy)
+				}
+
+				containerImage, err = image.EnsureBaked(commandConfig.Name, containerImage, beforeScript, bakeEnv)
+				if err != nil {
+					log.Fatal().Err(err).Msg("failed to bake before_script into image")
+				}
+			}
+			container.SetImage(containerImage)
+		} else if commandConfig.BeforeScript != nil {
 			commandWithBeforeScript = strings.Join(commandConfig.BeforeScript[:], ";") + " && " + commandWithBeforeScript
 
 			commandWithBeforeScript = strings.Replace(commandWithBeforeScript, "{HTTPProxy}", collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""), -1)
 			commandWithBeforeScript = strings.Replace(commandWithBeforeScript, "{HTTPSProxy}", collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""), -1)
 		}
+		if shellWrapped {
+			// the command shell is passed in double quotes to the host shell, which must not expand anything - globs and variables are expanded by the shell inside of the container
+			commandWithBeforeScript = common.EscapeDoubleQuoted(commandWithBeforeScript)
+		}
 		log.Debug().Msg("Setting new command with before_script: " + commandWithBeforeScript)
 		container.SetCommand(commandWithBeforeScript)
 
@@ -91,15 +132,66 @@ var runCmd = &cobra.Command{
 		}
 
 		// feature: caching
+		cachePath, cacheEnabled := cache.GetCachePath(propConfig.Properties)
+		if len(commandConfig.Caching) > 0 && !cacheEnabled {
+			log.Debug().Msg("Cache is disabled, cache-path is set to off.")
+		}
+		var usedCaches []cache.Usage
+		var lockedCaches []string
 		for _, cachingEntry := range commandConfig.Caching {
-			if collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", "") == "" {
-				log.Warn().Msg("Cache is disabled, CachePath not set.")
+			if !cacheEnabled {
 				break
 			}
 
-			var cacheFolder = collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", "") + "/" + cachingEntry.Name
-			filesystem.CreateDirectory(cacheFolder)
+			switch cachingEntry.Type {
+			case "", cache.TypeDirectory, cache.TypeVolume:
+			default:
+				log.Warn().Str("cache", cachingEntry.Name).Str("type", cachingEntry.Type).Msg("invalid cache type, allowed: directory, volume - skipping cache")
+				continue
+			}
+
+			// isolate the cache according to its scope
+			cacheImage := commandConfig.Image
+			if cacheImage == "" {
+				cacheImage = containerImage
+			}
+			cacheKey, err := cache.Key(cachingEntry.Name, cachingEntry.Scope, projectOrExecutionDir, cacheImage)
+			if err != nil {
+				log.Warn().Err(err).Msg("skipping cache")
+				continue
+			}
+			usage := cache.Usage{Key: cacheKey, Cache: cachingEntry.Name, Scope: cachingEntry.Scope, Project: projectOrExecutionDir}
+			switch cachingEntry.Lock {
+			case "":
+			case cache.LockExclusive:
+				lockedCaches = append(lockedCaches, cacheKey)
+			default:
+				log.Warn().Str("cache", cachingEntry.Name).Str("lock", cachingEntry.Lock).Msg("invalid cache lock, allowed: exclusive")
+			}
+
+			if cachingEntry.Type == cache.TypeVolume {
+				volumeName := cache.VolumeName(cacheKey)
+				if !dryRun {
+					volumeName, err = cache.EnsureVolume(cacheKey, cachingEntry.Name)
+					if err != nil {
+						log.Warn().Err(err).Str("cache", cachingEntry.Name).Msg("failed to create cache volume, skipping cache")
+						continue
+					}
+				}
+				// named volumes are passed as mount source, the volume mount type of the runtime lib would use a temp directory on podman
+				container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: volumeName, Target: cachingEntry.ContainerDirectory})
+				usage.Key = volumeName
+				usedCaches = append(usedCaches, usage)
+				continue
+			}
+
+			cacheFolder, err := cache.CreateDirectory(cachePath, cacheKey)
+			if err != nil {
+				log.Warn().Err(err).Str("cache", cachingEntry.Name).Msg("failed to create cache directory, skipping cache")
+				continue
+			}
 			container.AddCacheMount(cachingEntry.Name, cacheFolder, cachingEntry.ContainerDirectory)
+			usedCaches = append(usedCaches, usage)
 		}
 
 		// feature: capabilities
@@ -123,8 +215,85 @@ var runCmd = &cobra.Command{
 			container.AddEnvironmentVariable("https_proxy", httpsProxy)
 		}
 
+		// dry-run: print the command instead of executing it
+		if dryRun {
+			if commandConfig.Build != nil {
+				fmt.Printf("Build: %s\n", buildSpec(commandConfig).Context)
+			} else {
+				fmt.Printf("Image: %s\n", commandConfig.Image)
+			}
+			if containerImage != commandConfig.Image {
+				fmt.Printf("Resolved Image: %s\n", containerImage)
+			}
+			runtime := container.DetectRuntime()
+			if runtime == "unknown" {
+				runtime = "docker"
+			}
+			runCommand, err := container.GetRunCommand(runtime)
+			common.CheckForError(err)
+			fmt.Println(runCommand)
+			return
+		}
+
 		// detect container service and send command
-		log.Info().Msg("Executing command in container [" + commandConfig.Image + "].")
+		log.Info().Msg("Executing command in container [" + containerImage + "].")
+		if err := cache.RecordUsage(cachePath, usedCaches, containerImage); err != nil {
+			log.Warn().Err(err).Msg("failed to record cache usage")
+		}
+		sort.Strings(lockedCaches) // same order in all runs, to avoid deadlocks
+		for _, cacheKey := range lockedCaches {
+			lock, err := cache.Lock(cachePath, cacheKey)
+			if err != nil {
+				log.Fatal().Err(err).Str("cache", cacheKey).Msg("failed to lock cache")
+			}
+			defer lock.Unlock()
+		}
 		container.StartContainer()
+
+		// feature: cache size limit
+		if cacheEnabled {
+			evictCaches(cachePath, usedCaches)
+		}
 	},
 }
+
+// commandImage returns the image for the command, images with a build configuration are built on first use if build is true
+func commandImage(element config.RunConfigurationEntry, build bool) (string, error) {
+	if element.Build != nil {
+		if !build {
+			return image.BuildTag(element.Name, buildSpec(element))
+		}
+		return image.EnsureBuilt(element.Name, buildSpec(element), false)
+	}
+
+	return resolveImage(element.Image), nil
+}
+
+// resolveImage pins the image to the digest from the lock file and applies the registry-mirrors rewrite rules
+func resolveImage(reference string) string {
+	resolved := mirrorImage(config.GetPinnedImage(reference))
+	if resolved != reference {
+		log.Debug().Str("image", reference).Str("resolved", resolved).Msg("resolved image reference")
+	}
+
+	return resolved
+}
+
+// mirrorImage applies the registry-mirrors rewrite rules to the image reference
+func mirrorImage(reference string) string {
+	rules, err := image.ParseMirrorRules(collection.MapGetValueOrDefault(propConfig.Properties, "registry-mirrors", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid registry-mirrors property")
+	}
+
+	return image.Rewrite(reference, rules)
+}
+
+// commandArguments quotes each argument, so the command receives the original argv - with a command shell the arguments are passed unquoted, so the shell inside of the container expands globs and variables
+func commandArguments(args []string, shellWrapped bool) string {
+	if shellWrapped {
+		return strings.Join(args, " ")
+	}
+
+	return common.ParseAndEscapeArgs(args)
+}
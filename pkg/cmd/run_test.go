@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+)
+
+// runInShell runs the command like the container runtime lib, the host shell runs the command shell with the command in double quotes
+func runInShell(t *testing.T, dir string, command string, shellWrapped bool) string {
+	if shellWrapped {
+		command = `"/usr/bin/env" "sh" "-c" "` + strings.ReplaceAll(common.EscapeDoubleQuoted(command), "\"", "\\\"") + `"`
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TOOL_VERSION=1.0")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func TestCommandArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"echo", "*.go", "$TOOL_VERSION"}
+
+	// with a command shell globs and variables are expanded inside of the container
+	if output := runInShell(t, dir, commandArguments(args, true), true); output != "a.go b.go 1.0" {
+		t.Errorf("expected the command shell to expand the arguments, got %q", output)
+	}
+
+	// without a command shell the command receives the original argv
+	if output := runInShell(t, dir, commandArguments(args, false), false); output != "*.go $TOOL_VERSION" {
+		t.Errorf("expected the original arguments, got %q", output)
+	}
+}
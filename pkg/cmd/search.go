@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/catalog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search [term]",
+	Short: "searches the tool catalog, lists all tools if no term is specified",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		term := ""
+		if len(args) > 0 {
+			term = args[0]
+		}
+
+		toolCatalog := loadCatalog()
+		tools := toolCatalog.Search(term)
+		if len(tools) == 0 {
+			fmt.Printf("No tools found for [%s].\n", term)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tPROVIDES\tDESCRIPTION")
+		for _, tool := range tools {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tool.Name, tool.Version, strings.Join(tool.Provides, ", "), tool.Description)
+		}
+		_ = w.Flush()
+	},
+}
+
+// loadCatalog loads the embedded catalog and the local catalog file, configured by the catalog-path property
+func loadCatalog() catalog.Catalog {
+	toolCatalog, err := catalog.Load(propConfig.Properties["catalog-path"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load the tool catalog")
+	}
+
+	return toolCatalog
+}
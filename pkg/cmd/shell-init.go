@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/aliases"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+	shellInitCmd.Flags().Bool("functions", false, "Prints the functions for the current directory, used by the directory-change hook")
+	_ = shellInitCmd.Flags().MarkHidden("functions")
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:       "shell-init [bash|zsh|fish|powershell]",
+	Short:     "prints the shell integration, that defines a function for each provided command of the current directory",
+	Long:      "Defines a shell function for each provided command, the functions are redefined whenever the current directory changes.\n\nbash/zsh: eval \"$(envcli shell-init bash)\"\nfish: envcli shell-init fish | source\npowershell: envcli shell-init powershell | Out-String | Invoke-Expression",
+	ValidArgs: aliases.Shells,
+	Args:      cobra.ExactValidArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions, _ := cmd.Flags().GetBool("functions")
+		configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+		shell := args[0]
+
+		executable, err := os.Executable()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to detect the envcli executable")
+		}
+
+		// directory-change hook
+		if !functions {
+			script, err := aliases.ShellInit(shell, executable)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to generate shell integration")
+			}
+			fmt.Print(script)
+			return
+		}
+
+		// functions for the provided commands of the current directory
+		var commands []string
+		if resolvedConfig, err := config.ResolveConfiguration(filesystem.GetWorkingDirectory(), configIncludes); err == nil {
+			for _, element := range resolvedConfig.Images {
+				if element.Alias != "false" {
+					commands = append(commands, element.Provides...)
+				}
+			}
+		}
+
+		script, err := aliases.ShellFunctions(shell, executable, commands)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to generate shell functions")
+		}
+		fmt.Print(script)
+	},
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/updater"
+	"github.com/cidverse/cidverseutils/pkg/cihelper"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// updateCheckInterval is the minimum time between two background update checks
+	updateCheckInterval = 24 * time.Hour
+	// updateCheckTimeout limits the duration of the background update check
+	updateCheckTimeout = 5 * time.Second
+	// updateCheckWait is how long a finished command waits for a pending update check
+	updateCheckWait = 500 * time.Millisecond
+)
+
+// updateCheckResult receives the latest version from the background update check, nil if no check is running
+var updateCheckResult chan string
+
+// updateCheckSkipped are the commands that never check for updates
+var updateCheckSkipped = []string{"self-update", "shell-init", "version", "completion", "help"}
+
+// latestVersion queries the latest release for the background update check
+var latestVersion = func() (string, error) {
+	appUpdater := newAppUpdater()
+	appUpdater.HTTPClient = &http.Client{Timeout: updateCheckTimeout}
+	return appUpdater.LatestVersion()
+}
+
+// releasePublicKey returns the embedded key that verifies the signature of updates
+var releasePublicKey = updater.ReleasePublicKey
+
+// updatePropertyConfig persists the update check state
+var updatePropertyConfig = config.UpdatePropertyConfig
+
+// startUpdateCheck starts the background update check, if the last check is older than the update check interval
+func startUpdateCheck(cmd *cobra.Command) {
+	if _, disabled := os.LookupEnv("ENVCLI_NO_UPDATE_CHECK"); disabled || cihelper.IsCIEnvironment() {
+		return
+	}
+	if found, _ := collection.InArray(cmd.Name(), updateCheckSkipped); found {
+		return
+	}
+	if !updater.IsNewerVersion(Version, "0.0.0") {
+		log.Trace().Str("version", Version).Msg("skipping update check for development builds")
+		return
+	}
+	if releasePublicKey() == "" && propConfig.Properties["update-verify-signature"] != "false" {
+		log.Trace().Msg("skipping update check, this build has no release public key to verify updates")
+		return
+	}
+
+	lastUpdateCheck, _ := strconv.ParseInt(collection.MapGetValueOrDefault(propConfig.Properties, "last-update-check", "0"), 10, 64)
+	if time.Since(time.Unix(lastUpdateCheck, 0)) < updateCheckInterval {
+		return
+	}
+
+	// the check is recorded when it starts, so a slow or unreachable update source doesn't delay every command
+	err := updatePropertyConfig(func(cfg *config.PropertyConfigurationFile) {
+		cfg.Properties["last-update-check"] = strconv.FormatInt(time.Now().Unix(), 10)
+	})
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to save the update check time")
+	}
+
+	lookup, result := latestVersion, make(chan string, 1)
+	updateCheckResult = result
+	go func() {
+		version, err := lookup()
+		if err != nil {
+			log.Debug().Err(err).Msg("update check failed")
+		}
+		result <- version
+	}()
+}
+
+// finishUpdateCheck records the result of the background update check and prints a notice if a newer version is available
+func finishUpdateCheck() {
+	if updateCheckResult == nil {
+		return
+	}
+
+	newestVersion := collection.MapGetValueOrDefault(propConfig.Properties, "latest-version", "")
+	select {
+	case result := <-updateCheckResult:
+		if result != "" {
+			newestVersion = result
+			err := updatePropertyConfig(func(cfg *config.PropertyConfigurationFile) {
+				cfg.Properties["latest-version"] = result
+			})
+			if err != nil {
+				log.Debug().Err(err).Msg("failed to save the update check result")
+			}
+		}
+	case <-time.After(updateCheckWait):
+		log.Debug().Msg("update check didn't finish in time, using the previous result")
+	}
+
+	if updater.IsNewerVersion(newestVersion, Version) {
+		fmt.Fprintf(os.Stderr, "A new version of envcli is available (%s -> %s), run `envcli self-update` to update.\n", Version, newestVersion)
+	}
+}
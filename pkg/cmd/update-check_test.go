@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// fakeUpdateCheck replaces the release lookup and the property file of the update check, the returned properties receive all saved values
+func fakeUpdateCheck(t *testing.T, version string, lastCheck time.Time, lookup func() (string, error)) map[string]string {
+	t.Setenv("CI", "false")
+	t.Setenv("ENVCLI_NO_UPDATE_CHECK", "")
+	os.Unsetenv("ENVCLI_NO_UPDATE_CHECK")
+	saved := make(map[string]string)
+
+	originalVersion, originalConfig, originalLookup, originalUpdate, originalKey := Version, propConfig, latestVersion, updatePropertyConfig, releasePublicKey
+	Version = version
+	releasePublicKey = func() string { return "MFkwEwYHKoZIzj0CAQ==" }
+	propConfig = config.PropertyConfigurationFile{Properties: map[string]string{"last-update-check": strconv.FormatInt(lastCheck.Unix(), 10)}}
+	latestVersion = lookup
+	updatePropertyConfig = func(update func(cfg *config.PropertyConfigurationFile)) error {
+		cfg := config.PropertyConfigurationFile{Properties: saved}
+		update(&cfg)
+		return nil
+	}
+	updateCheckResult = nil
+	t.Cleanup(func() {
+		Version, propConfig, latestVersion, updatePropertyConfig, releasePublicKey = originalVersion, originalConfig, originalLookup, originalUpdate, originalKey
+		updateCheckResult = nil
+	})
+
+	return saved
+}
+
+// captureStderr returns everything written to stderr by the function
+func captureStderr(t *testing.T, f func()) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stderr
+	os.Stderr = writer
+	f()
+	os.Stderr = original
+	writer.Close()
+
+	output, _ := io.ReadAll(reader)
+	return string(output)
+}
+
+func TestUpdateCheckSkipped(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		version string
+		env     map[string]string
+		noKey   bool
+	}{
+		{name: "disabled", command: "run", version: "1.0.0", env: map[string]string{"ENVCLI_NO_UPDATE_CHECK": "1"}},
+		{name: "ci", command: "run", version: "1.0.0", env: map[string]string{"CI": "true"}},
+		{name: "skipped command", command: "self-update", version: "1.0.0"},
+		{name: "development build", command: "run", version: "dev"},
+		{name: "no release key", command: "run", version: "1.0.0", noKey: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			saved := fakeUpdateCheck(t, c.version, time.Time{}, func() (string, error) {
+				t.Error("expected no update check")
+				return "", nil
+			})
+			for key, value := range c.env {
+				t.Setenv(key, value)
+			}
+			if c.noKey {
+				releasePublicKey = func() string { return "" }
+			}
+
+			startUpdateCheck(&cobra.Command{Use: c.command})
+			if updateCheckResult != nil || len(saved) != 0 {
+				t.Errorf("expected the update check to be skipped, saved %v", saved)
+			}
+		})
+	}
+}
+
+func TestUpdateCheckRateLimit(t *testing.T) {
+	saved := fakeUpdateCheck(t, "1.0.0", time.Now().Add(-time.Hour), func() (string, error) {
+		t.Error("expected no update check")
+		return "", nil
+	})
+
+	startUpdateCheck(&cobra.Command{Use: "run"})
+	if updateCheckResult != nil || len(saved) != 0 {
+		t.Errorf("expected no update check within the update check interval, saved %v", saved)
+	}
+}
+
+func TestUpdateCheckWithoutSignature(t *testing.T) {
+	saved := fakeUpdateCheck(t, "1.0.0", time.Time{}, func() (string, error) {
+		return "1.2.0", nil
+	})
+	releasePublicKey = func() string { return "" }
+	propConfig.Properties["update-verify-signature"] = "false"
+
+	startUpdateCheck(&cobra.Command{Use: "run"})
+	captureStderr(t, finishUpdateCheck)
+	if saved["latest-version"] != "1.2.0" {
+		t.Errorf("expected builds without a key to check for updates if signatures are disabled, got %v", saved)
+	}
+}
+
+func TestUpdateCheckResult(t *testing.T) {
+	saved := fakeUpdateCheck(t, "1.0.0", time.Now().Add(-2*updateCheckInterval), func() (string, error) {
+		return "1.2.0", nil
+	})
+
+	startUpdateCheck(&cobra.Command{Use: "run"})
+	output := captureStderr(t, finishUpdateCheck)
+
+	if saved["latest-version"] != "1.2.0" || saved["last-update-check"] == "" {
+		t.Errorf("expected the update check result to be saved, got %v", saved)
+	}
+	if !strings.Contains(output, "1.0.0 -> 1.2.0") {
+		t.Errorf("expected an update notice, got %q", output)
+	}
+}
+
+func TestUpdateCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	saved := fakeUpdateCheck(t, "1.0.0", time.Now().Add(-2*updateCheckInterval), func() (string, error) {
+		<-release
+		return "1.2.0", nil
+	})
+	propConfig.Properties["latest-version"] = "1.1.0"
+
+	startUpdateCheck(&cobra.Command{Use: "run"})
+	output := captureStderr(t, finishUpdateCheck)
+
+	lastCheck, _ := strconv.ParseInt(saved["last-update-check"], 10, 64)
+	if time.Since(time.Unix(lastCheck, 0)) > time.Minute {
+		t.Errorf("expected the time of the unfinished check to be saved, got %v", saved)
+	}
+	if _, found := saved["latest-version"]; found {
+		t.Errorf("expected no result to be saved, got %v", saved)
+	}
+	if !strings.Contains(output, "1.0.0 -> 1.1.0") {
+		t.Errorf("expected the notice to use the previous result, got %q", output)
+	}
+}
+
+func TestUpdateCheckFailed(t *testing.T) {
+	saved := fakeUpdateCheck(t, "1.0.0", time.Now().Add(-2*updateCheckInterval), func() (string, error) {
+		return "", errors.New("offline")
+	})
+
+	startUpdateCheck(&cobra.Command{Use: "run"})
+	output := captureStderr(t, finishUpdateCheck)
+
+	if saved["last-update-check"] == "" || saved["latest-version"] != "" {
+		t.Errorf("expected only the time of the failed check to be saved, got %v", saved)
+	}
+	if output != "" {
+		t.Errorf("expected no update notice, got %q", output)
+	}
+}
@@ -1,12 +1,12 @@
 package cmd
 
 import (
-	"strconv"
-	"time"
+	"fmt"
+	"os"
+	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/config"
 	"github.com/EnvCLI/EnvCLI/pkg/updater"
-	"github.com/cidverse/cidverseutils/pkg/cihelper"
-	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +15,14 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 	updateCmd.Flags().BoolP("force", "f", false, "A forced update would also redownload the current version.")
 	updateCmd.Flags().String("target", "latest", "A target version that should be upgraded/downgraded to.")
+	updateCmd.Flags().Bool("list", false, "Lists the available versions of the update channel with their release notes.")
+	updateCmd.Flags().Bool("rollback", false, "Restores the version that was replaced by the last update.")
+	updateCmd.Flags().Bool("history", false, "Lists the previous updates and rollbacks.")
+}
+
+// newAppUpdater returns the updater for envcli, configured by the global properties
+func newAppUpdater() updater.ApplicationUpdater {
+	return updater.ApplicationUpdater{GitHubOrg: "EnvCLI", GitHubRepository: "EnvCLI", PublicKey: releasePublicKey(), SkipSignature: propConfig.Properties["update-verify-signature"] == "false", UpdateURL: propConfig.Properties["update-url"], Channel: propConfig.Properties["update-channel"], StateDirectory: config.GetStateDirectory()}
 }
 
 var updateCmd = &cobra.Command{
@@ -23,16 +31,91 @@ var updateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		target, _ := cmd.Flags().GetString("target")
 		force, _ := cmd.Flags().GetBool("force")
+		list, _ := cmd.Flags().GetBool("list")
+		rollback, _ := cmd.Flags().GetBool("rollback")
+		history, _ := cmd.Flags().GetBool("history")
 
-		// Update Check, once a day (not in CI)
-		appUpdater := updater.ApplicationUpdater{GitHubOrg: "EnvCLI", GitHubRepository: "EnvCLI"}
-		var lastUpdateCheck, _ = strconv.ParseInt(collection.MapGetValueOrDefault(propConfig.Properties, "last-update-check", strconv.Itoa(int(time.Now().Unix()))), 10, 64)
-		if time.Now().Unix() >= lastUpdateCheck+86400 && cihelper.IsCIEnvironment() == false {
-			if appUpdater.IsUpdateAvailable(cmd.Version) {
-				log.Warn().Msg("You are using a old version, please consider to update using `envcli self-update`!")
+		appUpdater := newAppUpdater()
+
+		if list {
+			releases, err := appUpdater.AvailableReleases()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to list the available versions")
 			}
+			printReleases(releases, Version)
+			return
 		}
 
-		appUpdater.Update(target, force, cmd.Version)
+		if history {
+			state, err := updater.LoadUpdateState(appUpdater.StateDirectory)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load the update history")
+			}
+			printUpdateHistory(state)
+			return
+		}
+
+		// binaries managed by a package manager must be updated by the package manager
+		if executable, err := os.Executable(); err == nil {
+			if manager, managed := updater.PackageManager(executable); managed {
+				log.Error().Str("binary", executable).Msg("envcli was installed by " + manager + ", please use it to update envcli")
+				os.Exit(1)
+			}
+		}
+
+		if rollback {
+			if _, err := appUpdater.Rollback(Version); err != nil {
+				log.Fatal().Err(err).Msg("rollback failed")
+			}
+			return
+		}
+
+		if err := appUpdater.Update(target, force, Version); err != nil {
+			log.Fatal().Err(err).Msg("self-update failed")
+		}
 	},
 }
+
+// printReleases prints the version and release notes of each release
+func printReleases(releases []updater.Release, currentVersion string) {
+	for _, release := range releases {
+		var labels []string
+		if strings.TrimLeft(release.Version, "v") == strings.TrimLeft(currentVersion, "v") {
+			labels = append(labels, "installed")
+		}
+		if release.Prerelease {
+			labels = append(labels, "pre-release")
+		}
+
+		if len(labels) > 0 {
+			fmt.Printf("%s (%s)\n", release.Version, strings.Join(labels, ", "))
+		} else {
+			fmt.Println(release.Version)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(release.Notes), "\n") {
+			if strings.TrimSpace(line) != "" {
+				fmt.Println("  " + strings.TrimRight(line, "\r"))
+			}
+		}
+	}
+}
+
+// printUpdateHistory prints the previous updates and rollbacks, newest first
+func printUpdateHistory(state updater.UpdateState) {
+	if len(state.History) == 0 {
+		fmt.Println("No updates recorded.")
+		return
+	}
+
+	for i := len(state.History) - 1; i >= 0; i-- {
+		entry := state.History[i]
+		kind := "update"
+		if entry.Rollback {
+			kind = "rollback"
+		}
+		fmt.Printf("%s  %-8s  %s -> %s\n", entry.Date.Local().Format("2006-01-02 15:04"), kind, entry.From, entry.To)
+	}
+	if state.Previous != nil {
+		fmt.Printf("\n%s can be restored with `envcli self-update --rollback`.\n", state.Previous.Version)
+	}
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileLock is a advisory inter-process lock backed by a lock file
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// NewFileLock returns a lock backed by the file at path, the file is created when the lock is acquired
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// Lock acquires the exclusive lock, blocks until the lock is available
+func (l *FileLock) Lock() error {
+	_, err := l.acquire(true)
+	return err
+}
+
+// TryLock acquires the exclusive lock if it is available, returns false if the lock is held by another process
+func (l *FileLock) TryLock() (bool, error) {
+	return l.acquire(false)
+}
+
+// Unlock releases the lock, the lock file is kept
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+func (l *FileLock) acquire(block bool) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return false, err
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return false, err
+	}
+
+	locked, err := lockFile(file, block)
+	if err != nil || !locked {
+		_ = file.Close()
+		return false, err
+	}
+	log.Trace().Str("file", l.path).Msg("acquired file lock")
+
+	l.file = file
+	return true, nil
+}
+
+// WriteFileAtomic writes the content to a temporary file next to the target and renames it, readers never see a partially written file
+func WriteFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempFile.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
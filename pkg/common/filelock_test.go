@@ -0,0 +1,42 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLockIsExclusive(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "cache.lock")
+	first := NewFileLock(lockPath)
+	second := NewFileLock(lockPath)
+
+	if err := first.Lock(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if locked, err := second.TryLock(); err != nil || locked {
+		t.Fatalf("expected the lock to be held, got %v (%v)", locked, err)
+	}
+
+	_ = first.Unlock()
+	if locked, err := second.TryLock(); err != nil || !locked {
+		t.Fatalf("expected the lock to be released, got %v (%v)", locked, err)
+	}
+	_ = second.Unlock()
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, ".envclirc")
+	_ = os.WriteFile(target, []byte("old"), 0600)
+
+	if err := WriteFileAtomic(target, []byte("new"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "new" {
+		t.Errorf("expected the content to be replaced, got %q", content)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
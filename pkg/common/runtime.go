@@ -0,0 +1,65 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/rs/zerolog/log"
+)
+
+// DetectContainerRuntime returns the first available container runtime (podman, docker)
+func DetectContainerRuntime() (string, error) {
+	runtime := (&containerruntime.Container{}).DetectRuntime()
+	if runtime == "unknown" {
+		return "", errors.New("no supported container runtime found (podman, docker)")
+	}
+
+	return runtime, nil
+}
+
+// RuntimeOutput runs the container runtime with the provided arguments and returns the trimmed combined output
+func RuntimeOutput(args ...string) (string, error) {
+	runtime, err := DetectContainerRuntime()
+	if err != nil {
+		return "", err
+	}
+
+	log.Trace().Str("runtime", runtime).Strs("args", args).Msg("executing container runtime command")
+	output, err := exec.Command(runtime, args...).CombinedOutput()
+	if err != nil {
+		return strings.TrimSpace(string(output)), errors.New(runtime + " " + args[0] + " failed: " + lastLine(string(output), err.Error()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RuntimeRun runs the container runtime with the provided arguments, the output is passed through to stderr to keep stdout clean
+func RuntimeRun(args ...string) error {
+	runtime, err := DetectContainerRuntime()
+	if err != nil {
+		return err
+	}
+
+	log.Trace().Str("runtime", runtime).Strs("args", args).Msg("executing container runtime command")
+	cmd := exec.Command(runtime, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.New(runtime + " " + args[0] + " failed: " + err.Error())
+	}
+
+	return nil
+}
+
+// lastLine returns the last non-empty line of the output, or the fallback if the output is empty
+func lastLine(output string, fallback string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+
+	return fallback
+}
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"bytes"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"runtime"
@@ -27,22 +26,44 @@ func SetLoglevel(loglevel string) {
 	}
 }
 
-// ParseAndEscapeArgs takes all cli arguments, quotes them and handles escaping
+// ParseAndEscapeArgs quotes each argument for the host shell (sh, powershell on windows) that starts the container, so the command receives the original argv
 func ParseAndEscapeArgs(args []string) string {
-	var commandWithArguments bytes.Buffer
+	quote := PosixQuote
+	if runtime.GOOS == "windows" {
+		quote = PowershellQuote
+	}
+
+	return QuoteArgs(args, quote)
+}
+
+// QuoteArgs quotes each argument with the quote function and joins them
+func QuoteArgs(args []string, quote func(string) string) string {
+	var quotedArgs []string
 	for _, arg := range args {
 		log.Trace().Msg("Parsing arg: " + arg)
-		if runtime.GOOS == "windows" {
-			quotedArg := "\"" + strings.Replace(strings.Trim(arg, "\""), "\"", "`\"", -1) + "\""
-			commandWithArguments.WriteString(quotedArg + " ")
-		} else {
-			quotedArg := "\"" + strings.Replace(strings.Trim(arg, "\""), "\"", "\\\"", -1) + "\""
-			commandWithArguments.WriteString(quotedArg + " ")
-		}
+		quotedArgs = append(quotedArgs, quote(arg))
+	}
+
+	return strings.Join(quotedArgs, " ")
+}
+
+// PosixQuote quotes the value for sh, bash and zsh - nothing inside of single quotes is expanded
+func PosixQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// PowershellQuote quotes the value for powershell - nothing inside of single quotes is expanded
+func PowershellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// EscapeDoubleQuoted escapes the characters that the host shell expands inside of double quotes, the double quotes are escaped by the container runtime lib
+func EscapeDoubleQuoted(command string) string {
+	if runtime.GOOS == "windows" {
+		return strings.NewReplacer("`", "``", "$", "`$").Replace(command)
 	}
 
-	command := commandWithArguments.String()
-	return command[:len(command)-1]
+	return strings.NewReplacer(`\`, `\\`, "$", `\$`, "`", "\\`").Replace(command)
 }
 
 // CheckForError checks if a error happened and logs it, and ends the process
@@ -1,6 +1,9 @@
 package common
 
 import (
+	"os/exec"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -10,10 +13,44 @@ func TestParseAndEscapeArgs(t *testing.T) {
 	testArgs = append(testArgs, "build")
 	testArgs = append(testArgs, "-ldflags=-w -X main.Example=common")
 
-	AssertStringEquals(t, ParseAndEscapeArgs(testArgs), "\"go\" \"build\" \"-ldflags=-w -X main.Example=common\"")
+	AssertStringEquals(t, ParseAndEscapeArgs(testArgs), "'go' 'build' '-ldflags=-w -X main.Example=common'")
 
 }
 
+// specialArgs contain characters that the host shell would expand or that break the quoting
+var specialArgs = []string{"printf", "%s\\n", "$HOME", "`id`", "$(id)", "trailing\\", "\"quoted\"", "it's", "a b", ""}
+
+func TestParseAndEscapeArgsHostShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	// the container runtime lib runs the command with sh -c
+	output, err := exec.Command("sh", "-c", ParseAndEscapeArgs(specialArgs)).Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := strings.Join(specialArgs[2:], "\n") + "\n"; string(output) != expected {
+		t.Errorf("expected the original argv %q, got %q", expected, output)
+	}
+}
+
+func TestEscapeDoubleQuoted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	// the container runtime lib wraps the command into "sh" "-c" "<command>" and only escapes the double quotes
+	command := strings.ReplaceAll(EscapeDoubleQuoted(QuoteArgs(specialArgs, PosixQuote)), "\"", "\\\"")
+	output, err := exec.Command("sh", "-c", `"/usr/bin/env" "sh" "-c" "`+command+`"`).Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := strings.Join(specialArgs[2:], "\n") + "\n"; string(output) != expected {
+		t.Errorf("expected the original argv %q, got %q", expected, output)
+	}
+}
+
 func AssertStringEquals(t *testing.T, value string, expected string) {
 	if value != expected {
 		t.Errorf("Failed to correctly parse the provided arguments! Expected: " + expected + ", got " + value)
@@ -2,10 +2,12 @@ package config
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/common"
 	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/jinzhu/configor"
@@ -19,7 +21,7 @@ var defaultConfigurationDirectory = filesystem.GetExecutionDirectory()
 var defaultConfigurationFile = ".envclirc"
 
 // Constants
-var validConfigurationOptions = []string{"http-proxy", "https-proxy", "global-configuration-path", "cache-path", "last-update-check"}
+var validConfigurationOptions = []string{"http-proxy", "https-proxy", "global-configuration-path", "cache-path", "last-update-check", "registry-mirrors", "cache-max-size", "alias-path", "update-url", "update-channel", "update-verify-signature", "latest-version", "catalog-path"}
 
 // LoadProjectConfig loads the project configuration
 func LoadProjectConfig(configFile string) (ConfigurationFile, error) {
@@ -34,10 +36,20 @@ func LoadProjectConfig(configFile string) (ConfigurationFile, error) {
 
 	decoder := yaml.NewDecoder(file)
 	err = decoder.Decode(&cfg)
-	if err != nil {
+	if err != nil && err != io.EOF {
 		return ConfigurationFile{}, err
 	}
 
+	for i := range cfg.Images {
+		cfg.Images[i].Source = configFile
+
+		switch cfg.Images[i].Alias {
+		case "", "false", "force":
+		default:
+			return ConfigurationFile{}, errors.New("invalid alias setting [" + cfg.Images[i].Alias + "] of image [" + cfg.Images[i].Name + "] in " + configFile + ", allowed: false, force")
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -49,7 +61,7 @@ func LoadPropertyConfig() (PropertyConfigurationFile, error) {
 		return LoadPropertyConfigFile(defaultConfigurationDirectory + "/" + defaultConfigurationFile)
 	}
 
-	return PropertyConfigurationFile{}, nil
+	return PropertyConfigurationFile{Properties: make(map[string]string)}, nil
 }
 
 // LoadPropertyConfigFile loads the property config file
@@ -68,8 +80,37 @@ func SavePropertyConfig(cfg PropertyConfigurationFile) error {
 	return SavePropertyConfigFile(defaultConfigurationDirectory+"/"+defaultConfigurationFile, cfg)
 }
 
-// SavePropertyConfigFile saves the property file
+// SavePropertyConfigFile saves the property file, concurrent writers are serialized by a lock file
 func SavePropertyConfigFile(configFile string, cfg PropertyConfigurationFile) error {
+	lock := common.NewFileLock(configFile + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return writePropertyConfigFile(configFile, cfg)
+}
+
+// UpdatePropertyConfig loads, modifies and saves the global property config while holding the lock, so concurrent updates aren't lost
+func UpdatePropertyConfig(update func(cfg *PropertyConfigurationFile)) error {
+	configFile := defaultConfigurationDirectory + "/" + defaultConfigurationFile
+	lock := common.NewFileLock(configFile + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	propConfig, err := LoadPropertyConfig()
+	if err != nil {
+		return err
+	}
+	update(&propConfig)
+
+	return writePropertyConfigFile(configFile, propConfig)
+}
+
+// writePropertyConfigFile replaces the property file atomically
+func writePropertyConfigFile(configFile string, cfg PropertyConfigurationFile) error {
 	log.Debug().Msg("Saving property configuration file " + configFile)
 
 	fileContent, err := yaml.Marshal(&cfg)
@@ -77,21 +118,19 @@ func SavePropertyConfigFile(configFile string, cfg PropertyConfigurationFile) er
 		return err
 	}
 
-	return os.WriteFile(configFile, fileContent, 0600)
+	return common.WriteFileAtomic(configFile, fileContent, 0600)
 }
 
 // SetPropertyConfigEntry sets a property in the property config
 func SetPropertyConfigEntry(varName string, varValue string) {
-	// Load Config
-	propConfig, _ := LoadPropertyConfig()
-
-	// Set value
 	isValidValue, _ := collection.InArray(varName, validConfigurationOptions)
 	if isValidValue {
-		propConfig.Properties[varName] = varValue
-
-		// Save Config
-		SavePropertyConfig(propConfig)
+		err := UpdatePropertyConfig(func(cfg *PropertyConfigurationFile) {
+			cfg.Properties[varName] = varValue
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to save property configuration")
+		}
 	}
 }
 
@@ -111,16 +150,14 @@ func GetPropertyConfigEntry(varName string) string {
 
 // UnsetPropertyConfigEntry clears a property
 func UnsetPropertyConfigEntry(varName string) {
-	// Load Config
-	propConfig, _ := LoadPropertyConfig()
-
-	// Get Value
 	isValidValue, _ := collection.InArray(varName, validConfigurationOptions)
 	if isValidValue {
-		propConfig.Properties[varName] = ""
-
-		// Save Config
-		SavePropertyConfig(propConfig)
+		err := UpdatePropertyConfig(func(cfg *PropertyConfigurationFile) {
+			cfg.Properties[varName] = ""
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to save property configuration")
+		}
 	}
 }
 
@@ -161,52 +198,68 @@ func GetProjectDirectory() (string, error) {
 	return "", errors.New("didn't find a envcli project config in any parent directories")
 }
 
-// MergeConfigurations merges two configurations and keep the origin in the scope
-func MergeConfigurations(configProject ConfigurationFile, configGlobal ConfigurationFile) ConfigurationFile {
-	var cfg = ConfigurationFile{}
+// MergeConfigurations appends the entries of the additional configuration with a lower precedence and keeps their origin in the scope
+func MergeConfigurations(cfg ConfigurationFile, additional ConfigurationFile, scope string) ConfigurationFile {
+	var merged = ConfigurationFile{Version: cfg.Version}
+	merged.Images = append(merged.Images, cfg.Images...)
 
-	for _, image := range configProject.Images {
-		image.Scope = "Project"
-		cfg.Images = append(cfg.Images, image)
-	}
-	for _, image := range configGlobal.Images {
-		image.Scope = "Global"
-		cfg.Images = append(cfg.Images, image)
+	for _, image := range additional.Images {
+		image.Scope = scope
+		merged.Images = append(merged.Images, image)
 	}
 
-	return cfg
+	return merged
 }
 
-// GetCommandConfiguration gets the configuration entry for a specified command in the specified directory
-func GetCommandConfiguration(commandName string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, error) {
+// ResolveConfiguration loads and merges all configuration files (project, includes, global) for the specified directory
+func ResolveConfiguration(currentDirectory string, customIncludes []string) (ConfigurationFile, error) {
 	// Global Configuration
 	propConfig, propConfigErr := LoadPropertyConfig()
 	if propConfigErr != nil {
 		// error, when loading the config
-		var emptyEntry RunConfigurationEntry
-		return emptyEntry, propConfigErr
+		return ConfigurationFile{}, propConfigErr
 	}
 
-	// Configuration file list
+	// Configuration file list, in order of precedence
 	var configFiles []string
+	var configScopes []string
 	// - project directory
 	projectDir, projectDirErr := GetProjectDirectory()
 	if projectDirErr == nil {
 		log.Debug().Msg("Project Directory: " + projectDir)
 		configFiles = append(configFiles, projectDir+"/.envcli.yml")
+		configScopes = append(configScopes, ScopeProject)
 	}
 	// - custom includes
-	configFiles = append(configFiles, customIncludes...)
+	for _, include := range customIncludes {
+		configFiles = append(configFiles, include)
+		configScopes = append(configScopes, ScopeInclude)
+	}
 	// - global (user-scope) configuration
 	var globalConfigPath = collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-path", defaultConfigurationDirectory)
 	log.Debug().Msg("Will load the global configuration from " + globalConfigPath + ".")
 	configFiles = append(configFiles, globalConfigPath+"/.envcli.yml")
+	configScopes = append(configScopes, ScopeGlobal)
 
 	// load configuration files
 	var finalConfiguration ConfigurationFile
-	for _, configFile := range configFiles {
-		configContent, _ := LoadProjectConfig(configFile)
-		finalConfiguration = MergeConfigurations(finalConfiguration, configContent)
+	for i, configFile := range configFiles {
+		configContent, err := LoadProjectConfig(configFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return ConfigurationFile{}, err
+		}
+		finalConfiguration = MergeConfigurations(finalConfiguration, configContent, configScopes[i])
+	}
+
+	return finalConfiguration, nil
+}
+
+// GetCommandConfiguration gets the configuration entry for a specified command in the specified directory
+func GetCommandConfiguration(commandName string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, error) {
+	finalConfiguration, err := ResolveConfiguration(currentDirectory, customIncludes)
+	if err != nil {
+		var emptyEntry RunConfigurationEntry
+		return emptyEntry, err
 	}
 
 	// search for command definition
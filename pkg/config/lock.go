@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"sort"
+
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// LockFileName is the name of the image lock file next to the project configuration
+const LockFileName = ".envcli.lock"
+
+// LockFile pins the images of the project configuration to their digests
+type LockFile struct {
+	Version string      `yaml:"version"`
+	Images  []LockEntry `yaml:"images"`
+}
+
+// LockEntry holds the resolved digest of a single image
+type LockEntry struct {
+	Image  string `yaml:"image"`
+	Digest string `yaml:"digest"`
+}
+
+// LoadLockFile loads the lock file
+func LoadLockFile(lockFile string) (LockFile, error) {
+	log.Debug().Msg("Loading lock file " + lockFile)
+	var lock LockFile
+
+	content, err := os.ReadFile(lockFile)
+	if err != nil {
+		return LockFile{}, err
+	}
+
+	err = yaml.Unmarshal(content, &lock)
+	if err != nil {
+		return LockFile{}, err
+	}
+
+	return lock, nil
+}
+
+// SaveLockFile saves the lock file, entries are sorted to keep the file stable
+func SaveLockFile(lockFile string, lock LockFile) error {
+	log.Debug().Msg("Saving lock file " + lockFile)
+
+	lock.Version = "v1"
+	sort.Slice(lock.Images, func(i, j int) bool {
+		return lock.Images[i].Image < lock.Images[j].Image
+	})
+	fileContent, err := yaml.Marshal(&lock)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(lockFile, fileContent, 0644)
+}
+
+// GetDigest returns the pinned digest of the image
+func (l LockFile) GetDigest(image string) (string, bool) {
+	for _, entry := range l.Images {
+		if entry.Image == image {
+			return entry.Digest, true
+		}
+	}
+
+	return "", false
+}
+
+// Diff compares the locked images with the provided images and returns the images missing in the lock file and the locked images that are no longer used
+func (l LockFile) Diff(images []string) (missing []string, unused []string) {
+	used := make(map[string]bool)
+	for _, image := range images {
+		used[image] = true
+		if _, ok := l.GetDigest(image); !ok {
+			missing = append(missing, image)
+		}
+	}
+	for _, entry := range l.Images {
+		if !used[entry.Image] {
+			unused = append(unused, entry.Image)
+		}
+	}
+
+	return missing, unused
+}
+
+// GetPinnedImage returns the image pinned to the digest from the project lock file, or the unchanged image if there is no lock entry
+func GetPinnedImage(image string) string {
+	projectDir, err := GetProjectDirectory()
+	if err != nil {
+		return image
+	}
+
+	lock, err := LoadLockFile(projectDir + "/" + LockFileName)
+	if err != nil {
+		return image
+	}
+
+	if digest, ok := lock.GetDigest(image); ok {
+		log.Debug().Str("image", image).Str("digest", digest).Msg("using pinned image digest from lock file")
+		return registry.PinDigest(image, digest)
+	}
+
+	return image
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// GetCacheDirectory returns the default cache directory ($XDG_CACHE_HOME/envcli or the platform equivalent)
+func GetCacheDirectory() string {
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = os.UserCacheDir()
+		if err != nil {
+			cacheDir = os.TempDir()
+		}
+	}
+
+	return filepath.Join(cacheDir, "envcli")
+}
+
+// GetDataDirectory returns the default data directory ($XDG_DATA_HOME/envcli, ~/.local/share/envcli or %LOCALAPPDATA%\envcli on windows)
+func GetDataDirectory() string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" && runtime.GOOS == "windows" {
+		dataDir = os.Getenv("LOCALAPPDATA")
+	}
+	if dataDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "envcli")
+		}
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+
+	return filepath.Join(dataDir, "envcli")
+}
+
+// GetStateDirectory returns the default state directory ($XDG_STATE_HOME/envcli, ~/.local/state/envcli or %LOCALAPPDATA%\envcli\state on windows)
+func GetStateDirectory() string {
+	if stateDir := os.Getenv("XDG_STATE_HOME"); stateDir != "" {
+		return filepath.Join(stateDir, "envcli")
+	}
+	if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" && runtime.GOOS == "windows" {
+		return filepath.Join(localAppData, "envcli", "state")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "envcli", "state")
+	}
+	return filepath.Join(homeDir, ".local", "state", "envcli")
+}
@@ -1,5 +1,12 @@
 package config
 
+// Scopes of the configuration entries, in order of precedence
+const (
+	ScopeProject = "Project"
+	ScopeInclude = "Include"
+	ScopeGlobal  = "Global"
+)
+
 // ConfigurationLoader contains all methods to load/save configuration files
 type ConfigurationLoader struct {
 }
@@ -21,9 +28,15 @@ type RunConfigurationEntry struct {
 	// the commands provided by the image
 	Provides []string `yaml:"provides"`
 
+	// alias installation for the provided commands - false never installs aliases, force installs them even if they shadow a host binary
+	Alias string `yaml:"alias"`
+
 	// container image
 	Image string `yaml:"image"`
 
+	// build the container image from a Dockerfile instead of using a published image
+	Build *BuildEntry `yaml:"build"`
+
 	// target directory to mount your project inside the container
 	Directory string `default:"/project"`
 
@@ -36,6 +49,9 @@ type RunConfigurationEntry struct {
 	// commands that should run in the container before the actual command is executed
 	BeforeScript []string `yaml:"before_script"`
 
+	// bake the before_script into a cached derived image instead of running it on every invocation
+	Bake bool `yaml:"bake"`
+
 	// allows a container to access the container runtime on the host
 	ContainerRuntimeAccess bool `yaml:"containerRuntimeAccess"`
 
@@ -45,8 +61,29 @@ type RunConfigurationEntry struct {
 	// Caching of container-directories
 	Caching []CachingEntry `yaml:"cache"`
 
-	// the command scope (internal use only) - global or project
+	// the command scope (internal use only) - Project, Include or Global
 	Scope string `yaml:"scope"`
+
+	// the configuration file this entry was loaded from (internal use only)
+	Source string `yaml:"-"`
+}
+
+type BuildEntry struct {
+
+	/**
+	 * Build context directory, relative to the configuration file
+	 */
+	Context string `yaml:"context" default:"."`
+
+	/**
+	 * Dockerfile path, relative to the build context
+	 */
+	Dockerfile string `yaml:"dockerfile" default:"Dockerfile"`
+
+	/**
+	 * Build arguments
+	 */
+	Args map[string]string `yaml:"args"`
 }
 
 type CachingEntry struct {
@@ -60,6 +97,21 @@ type CachingEntry struct {
 	 * Directory inside the container that should be mounted on the host within the cache directory
 	 */
 	ContainerDirectory string `yaml:"directory" default:""`
+
+	/**
+	 * Cache backend, directory (host directory below the cache-path) or volume (named volume managed by the container runtime)
+	 */
+	Type string `yaml:"type" default:"directory"`
+
+	/**
+	 * Isolation of the cache, shared (all projects and images), project (per project directory) or image (per image reference)
+	 */
+	Scope string `yaml:"scope" default:"shared"`
+
+	/**
+	 * Locking of the cache, exclusive allows only one run at a time to use the cache (for tools that don't support concurrent writers)
+	 */
+	Lock string `yaml:"lock" default:""`
 }
 
 type PropertyConfigurationFile struct {
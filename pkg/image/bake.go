@@ -0,0 +1,89 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BakeLabel is the label that marks derived images with a baked before_script
+const BakeLabel = "io.envcli.bake"
+
+// BakeTag returns the local tag of the derived image, the tag is a hash of the base image and the script
+func BakeTag(name string, baseImage string, script []string) string {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, baseImage+"\x00")
+	for _, line := range script {
+		_, _ = io.WriteString(hash, line+"\x00")
+	}
+
+	return LocalRepository + "bake-" + SanitizeName(name) + ":" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// EnsureBaked builds the derived image with the script as RUN instruction on top of the base image, if it doesn't exist yet - the derived image keeps the entrypoint, cmd and env of the base image
+func EnsureBaked(name string, baseImage string, script []string, env []string) (string, error) {
+	tag := BakeTag(name, baseImage, script)
+	if Exists(tag) {
+		log.Debug().Str("tag", tag).Msg("baked image exists, skipping before_script")
+		return tag, nil
+	}
+
+	buildDir, err := os.MkdirTemp("", "envcli-bake-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(buildDir)
+	run, _ := json.Marshal([]string{"/usr/bin/env", "sh", "-c", strings.Join(script, " && ")})
+	dockerfile := "FROM " + baseImage + "\nRUN " + string(run) + "\nLABEL " + BakeLabel + "=" + SanitizeName(name) + "\n"
+	if err := os.WriteFile(filepath.Join(buildDir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return "", err
+	}
+
+	// proxy variables are predefined build args, they are available to RUN but not persisted in the image
+	args := []string{"build", "--tag", tag, "--file", filepath.Join(buildDir, "Dockerfile")}
+	for _, e := range env {
+		args = append(args, "--build-arg", e)
+	}
+	args = append(args, buildDir)
+
+	log.Info().Str("image", baseImage).Str("tag", tag).Msg("baking before_script into derived image")
+	if err := runtimeRun(args...); err != nil {
+		return "", err
+	}
+
+	return tag, nil
+}
+
+// ListBaked returns all derived images with a baked before_script
+func ListBaked() ([]string, error) {
+	output, err := runtimeOutput("images", "--filter", "label="+BakeLabel, "--format", "{{.Repository}}:{{.Tag}}")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(output), nil
+}
+
+// RemoveBaked removes all derived images with a baked before_script
+func RemoveBaked() ([]string, error) {
+	images, err := ListBaked()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, image := range images {
+		if _, err := runtimeOutput("rmi", image); err != nil {
+			return removed, err
+		}
+		removed = append(removed, image)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,148 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/cidverse/cidverseutils/pkg/encoding"
+	"github.com/rs/zerolog/log"
+)
+
+// LocalRepository is the repository prefix for images that are built or derived locally by envcli
+const LocalRepository = "localhost/envcli/"
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// BuildSpec describes how to build a image from a Dockerfile
+type BuildSpec struct {
+	// Context is the absolute path of the build context
+	Context string
+	// Dockerfile is the absolute path of the Dockerfile
+	Dockerfile string
+	// Args are passed as build arguments
+	Args map[string]string
+}
+
+// runtimeOutput and runtimeRun execute the container runtime, replaced in tests
+var (
+	runtimeOutput = common.RuntimeOutput
+	runtimeRun    = common.RuntimeRun
+)
+
+// BuildTag returns the local tag for the build, the tag is a hash of the build context, Dockerfile and build args - files excluded by the .dockerignore are not part of the hash
+func BuildTag(name string, spec BuildSpec) (string, error) {
+	hash := sha256.New()
+
+	// build context
+	patterns, err := loadDockerignore(spec)
+	if err != nil {
+		return "", err
+	}
+	walkIgnoredDirectories := hasExclusions(patterns)
+	err = filepath.Walk(spec.Context, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, _ := filepath.Rel(spec.Context, path)
+		relativePath = filepath.ToSlash(relativePath)
+		if info.IsDir() {
+			if info.Name() == ".git" || (!walkIgnoredDirectories && relativePath != "." && ignored(patterns, relativePath)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || ignored(patterns, relativePath) {
+			return nil
+		}
+
+		fileHash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		_, _ = io.WriteString(hash, "file\x00"+relativePath+"\x00"+fileHash+"\x00")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// dockerfile, may be outside of the context
+	dockerfileHash, err := hashFile(spec.Dockerfile)
+	if err != nil {
+		return "", err
+	}
+	_, _ = io.WriteString(hash, "dockerfile\x00"+dockerfileHash+"\x00")
+
+	// build args
+	var argNames []string
+	for argName := range spec.Args {
+		argNames = append(argNames, argName)
+	}
+	sort.Strings(argNames)
+	for _, argName := range argNames {
+		_, _ = io.WriteString(hash, "arg\x00"+argName+"="+spec.Args[argName]+"\x00")
+	}
+
+	return LocalRepository + SanitizeName(name) + ":" + hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// SanitizeName turns the name into a valid image repository name
+func SanitizeName(name string) string {
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-._")
+}
+
+// Exists checks if the image is present in the local image store
+func Exists(image string) bool {
+	_, err := runtimeOutput("image", "inspect", image)
+	return err == nil
+}
+
+// Build builds the image and tags it with the provided tag
+func Build(tag string, spec BuildSpec) error {
+	args := []string{"build", "--tag", tag, "--file", spec.Dockerfile}
+	var argNames []string
+	for argName := range spec.Args {
+		argNames = append(argNames, argName)
+	}
+	sort.Strings(argNames)
+	for _, argName := range argNames {
+		args = append(args, "--build-arg", argName+"="+spec.Args[argName])
+	}
+	args = append(args, spec.Context)
+
+	log.Info().Str("tag", tag).Str("context", spec.Context).Msg("building image")
+	return runtimeRun(args...)
+}
+
+// EnsureBuilt builds the image if no image for the current inputs exists yet, force will always rebuild the image
+func EnsureBuilt(name string, spec BuildSpec, force bool) (string, error) {
+	tag, err := BuildTag(name, spec)
+	if err != nil {
+		return "", err
+	}
+
+	if !force && Exists(tag) {
+		log.Debug().Str("tag", tag).Msg("image is up to date, skipping build")
+		return tag, nil
+	}
+
+	return tag, Build(tag, spec)
+}
+
+// hashFile returns the hex encoded sha256 hash of the file content
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return encoding.SHA256Hash(file)
+}
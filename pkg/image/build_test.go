@@ -0,0 +1,184 @@
+package image
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRuntime records the container runtime commands and the Dockerfiles of builds, images in the set exist in the local image store
+type fakeRuntime struct {
+	images      map[string]bool
+	commands    []string
+	dockerfiles []string
+}
+
+func newFakeRuntime(t *testing.T) *fakeRuntime {
+	runtime := &fakeRuntime{images: make(map[string]bool)}
+
+	originalOutput, originalRun := runtimeOutput, runtimeRun
+	runtimeOutput = func(args ...string) (string, error) {
+		runtime.commands = append(runtime.commands, strings.Join(args, " "))
+		if args[0] == "image" && args[1] == "inspect" && !runtime.images[args[2]] {
+			return "", errors.New("no such image")
+		}
+		return "", nil
+	}
+	runtimeRun = func(args ...string) error {
+		runtime.commands = append(runtime.commands, strings.Join(args, " "))
+		for i, arg := range args {
+			if arg == "--file" {
+				content, err := os.ReadFile(args[i+1])
+				if err != nil {
+					return err
+				}
+				runtime.dockerfiles = append(runtime.dockerfiles, string(content))
+			}
+		}
+		return nil
+	}
+	t.Cleanup(func() {
+		runtimeOutput, runtimeRun = originalOutput, originalRun
+	})
+
+	return runtime
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func buildTag(t *testing.T, spec BuildSpec) string {
+	tag, err := BuildTag("My Tool", spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tag
+}
+
+func TestBuildTag(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Dockerfile":              "FROM alpine\nCOPY src /src\n",
+		"src/main.go":             "package main",
+		"node_modules/a/index.js": "a",
+		"dist/app.js":             "app",
+		"docs/README.md":          "readme",
+		"docs/keep.md":            "keep",
+		".dockerignore":           "# generated files\nnode_modules\n/dist\n**/*.md\n!docs/keep.md\n",
+	})
+	spec := BuildSpec{Context: dir, Dockerfile: filepath.Join(dir, "Dockerfile"), Args: map[string]string{"VERSION": "1"}}
+
+	tag := buildTag(t, spec)
+	if !strings.HasPrefix(tag, LocalRepository+"my-tool:") {
+		t.Errorf("unexpected tag %s", tag)
+	}
+	if buildTag(t, spec) != tag {
+		t.Error("expected the tag to be stable")
+	}
+
+	// ignored files don't change the tag
+	writeFiles(t, dir, map[string]string{"node_modules/b/index.js": "b", "dist/app.js": "changed", "docs/README.md": "changed"})
+	if buildTag(t, spec) != tag {
+		t.Error("expected files excluded by the .dockerignore to not change the tag")
+	}
+
+	// files in the context, re-included files, the Dockerfile and the build args change the tag
+	changes := []func(){
+		func() { writeFiles(t, dir, map[string]string{"src/main.go": "package main\n"}) },
+		func() { writeFiles(t, dir, map[string]string{"docs/keep.md": "changed"}) },
+		func() { writeFiles(t, dir, map[string]string{"Dockerfile": "FROM alpine:3\nCOPY src /src\n"}) },
+		func() { spec.Args["VERSION"] = "2" },
+	}
+	for i, change := range changes {
+		change()
+		changed := buildTag(t, spec)
+		if changed == tag {
+			t.Errorf("expected change %d to change the tag", i)
+		}
+		tag = changed
+	}
+}
+
+func TestBuildTagDockerfileIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tool.Dockerfile":              "FROM alpine\n",
+		"tool.Dockerfile.dockerignore": "*\n!tool.Dockerfile\n",
+		"src/main.go":                  "package main",
+	})
+	spec := BuildSpec{Context: dir, Dockerfile: filepath.Join(dir, "tool.Dockerfile")}
+
+	tag := buildTag(t, spec)
+	writeFiles(t, dir, map[string]string{"src/main.go": "changed"})
+	if buildTag(t, spec) != tag {
+		t.Error("expected the Dockerfile specific ignore file to be applied")
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	cases := map[string]bool{
+		"node_modules":           true,
+		"node_modules/a/b.js":    true,
+		"web/node_modules/a.js":  false,
+		"target/classes/A.class": true,
+		"src/Main.java":          false,
+		"src/a.log":              true,
+		"a.log":                  true,
+		"important.log":          false,
+		"tmp1/file":              true,
+		"tmp12/file":             false,
+	}
+	patterns, err := parseDockerignore(strings.NewReader("node_modules\n**/*.log\n!important.log\ntarget/\ntmp?\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for relativePath, expected := range cases {
+		if ignored(patterns, relativePath) != expected {
+			t.Errorf("expected ignored(%s) to be %v", relativePath, expected)
+		}
+	}
+}
+
+func TestEnsureBuilt(t *testing.T) {
+	runtime := newFakeRuntime(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Dockerfile": "FROM alpine\n"})
+	spec := BuildSpec{Context: dir, Dockerfile: filepath.Join(dir, "Dockerfile"), Args: map[string]string{"B": "2", "A": "1"}}
+
+	tag, err := EnsureBuilt("tool", spec, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "build --tag " + tag + " --file " + spec.Dockerfile + " --build-arg A=1 --build-arg B=2 " + dir
+	if runtime.commands[len(runtime.commands)-1] != expected {
+		t.Errorf("expected the image to be built with %q, got %v", expected, runtime.commands)
+	}
+
+	// existing images are reused unless the build is forced
+	runtime.images[tag] = true
+	runtime.commands = nil
+	if _, err := EnsureBuilt("tool", spec, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runtime.commands) != 1 {
+		t.Errorf("expected only the image to be inspected, got %v", runtime.commands)
+	}
+	if _, err := EnsureBuilt("tool", spec, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(runtime.commands[len(runtime.commands)-1], "build ") {
+		t.Errorf("expected a forced rebuild, got %v", runtime.commands)
+	}
+}
@@ -0,0 +1,140 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is a single pattern of a .dockerignore file
+type ignorePattern struct {
+	pattern   *regexp.Regexp
+	exclusion bool
+}
+
+// loadDockerignore reads the ignore patterns of the build, a Dockerfile specific <Dockerfile>.dockerignore takes precedence over the .dockerignore of the context
+func loadDockerignore(spec BuildSpec) ([]ignorePattern, error) {
+	for _, name := range []string{spec.Dockerfile + ".dockerignore", filepath.Join(spec.Context, ".dockerignore")} {
+		file, err := os.Open(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return parseDockerignore(file)
+	}
+
+	return nil, nil
+}
+
+// parseDockerignore parses the patterns line by line, empty lines and comments are skipped
+func parseDockerignore(reader io.Reader) ([]ignorePattern, error) {
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		exclusion := strings.HasPrefix(line, "!")
+		if exclusion {
+			line = strings.TrimSpace(line[1:])
+		}
+		line = strings.TrimPrefix(path.Clean(filepath.ToSlash(line)), "/")
+		if line == "." || line == "" {
+			continue
+		}
+
+		pattern, err := regexp.Compile(ignorePatternRegexp(line))
+		if err != nil {
+			return nil, errors.New("invalid .dockerignore pattern " + line + ": " + err.Error())
+		}
+		patterns = append(patterns, ignorePattern{pattern: pattern, exclusion: exclusion})
+	}
+
+	return patterns, scanner.Err()
+}
+
+// ignorePatternRegexp converts the pattern into a regular expression, * and ? don't match the separator while ** matches any number of directories
+func ignorePatternRegexp(pattern string) string {
+	var result strings.Builder
+	result.WriteString("^")
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case inClass:
+			result.WriteByte(ch)
+			inClass = ch != ']'
+		case ch == '[':
+			result.WriteByte(ch)
+			inClass = true
+		case ch == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+			}
+			if i+1 == len(pattern) {
+				result.WriteString(".*")
+			} else {
+				result.WriteString("(.*/)?")
+			}
+		case ch == '*':
+			result.WriteString("[^/]*")
+		case ch == '?':
+			result.WriteString("[^/]")
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			result.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			result.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	result.WriteString("$")
+
+	return result.String()
+}
+
+// ignored checks if the slash separated path relative to the context is excluded, a pattern also excludes everything below a matching directory and the last matching pattern wins
+func ignored(patterns []ignorePattern, relativePath string) bool {
+	excluded := false
+	for _, p := range patterns {
+		if p.exclusion == !excluded {
+			continue
+		}
+		if matchesOrParentMatches(p.pattern, relativePath) {
+			excluded = !p.exclusion
+		}
+	}
+
+	return excluded
+}
+
+func matchesOrParentMatches(pattern *regexp.Regexp, relativePath string) bool {
+	for current := relativePath; current != "." && current != "/"; current = path.Dir(current) {
+		if pattern.MatchString(current) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasExclusions checks if any pattern re-includes files, ignored directories must be walked in that case
+func hasExclusions(patterns []ignorePattern) bool {
+	for _, p := range patterns {
+		if p.exclusion {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package image
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+)
+
+// MirrorRule rewrites image references starting with Prefix to start with Replacement instead
+type MirrorRule struct {
+	Prefix      string
+	Replacement string
+}
+
+// ParseMirrorRules parses the rules of the registry-mirrors property, format: `docker.io/=mirror.corp/dockerhub/,quay.io/=mirror.corp/quay/`
+func ParseMirrorRules(value string) ([]MirrorRule, error) {
+	var rules []MirrorRule
+	for _, rule := range strings.Split(value, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		pair := strings.SplitN(rule, "=", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, errors.New("invalid registry mirror rule [" + rule + "], expected format: prefix=replacement")
+		}
+		rules = append(rules, MirrorRule{Prefix: strings.TrimSpace(pair[0]), Replacement: strings.TrimSpace(pair[1])})
+	}
+
+	return rules, nil
+}
+
+// Rewrite applies the longest matching mirror rule to the image, docker hub references are matched in their normalized form (ex. `node:18` and `docker.io/node:18` both match as `docker.io/library/node:18`)
+func Rewrite(image string, rules []MirrorRule) string {
+	candidate := image
+	if ref, err := registry.ParseReference(image); err == nil && ref.Registry == registry.DefaultRegistry {
+		candidate = ref.String()
+	}
+
+	var match *MirrorRule
+	for i, rule := range rules {
+		if strings.HasPrefix(candidate, rule.Prefix) && (match == nil || len(rule.Prefix) > len(match.Prefix)) {
+			match = &rules[i]
+		}
+	}
+	if match != nil {
+		return match.Replacement + strings.TrimPrefix(candidate, match.Prefix)
+	}
+
+	return image
+}
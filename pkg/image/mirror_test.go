@@ -0,0 +1,48 @@
+package image
+
+import (
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	rules, err := ParseMirrorRules("docker.io/=mirror.corp/dockerhub/, quay.io/=mirror.corp/quay/,quay.io/cidverse/=mirror.corp/cidverse/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]string{
+		"docker.io/node:18":              "mirror.corp/dockerhub/library/node:18",
+		"node:18-alpine":                 "mirror.corp/dockerhub/library/node:18-alpine",
+		"envcli/envcli:latest":           "mirror.corp/dockerhub/envcli/envcli:latest",
+		"quay.io/other/image:1":          "mirror.corp/quay/other/image:1",
+		"quay.io/cidverse/build-go:1.20": "mirror.corp/cidverse/build-go:1.20",
+		"ghcr.io/cidverse/cid:latest":    "ghcr.io/cidverse/cid:latest",
+	}
+	for input, expected := range cases {
+		if rewritten := Rewrite(input, rules); rewritten != expected {
+			t.Errorf("expected %s to be rewritten to %s, got %s", input, expected, rewritten)
+		}
+	}
+}
+
+func TestRewriteDockerHubNormalized(t *testing.T) {
+	rules, err := ParseMirrorRules("docker.io/=mirror.corp/dockerhub/,docker.io/library/node=mirror.corp/node")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, image := range []string{"node:18", "docker.io/node:18", "docker.io/library/node:18"} {
+		if rewritten := Rewrite(image, rules); rewritten != "mirror.corp/node:18" {
+			t.Errorf("expected %s to be rewritten to mirror.corp/node:18, got %s", image, rewritten)
+		}
+	}
+	if rewritten := Rewrite("envcli/envcli", rules); rewritten != "mirror.corp/dockerhub/envcli/envcli:latest" {
+		t.Errorf("expected the image without tag to be rewritten to the latest tag, got %s", rewritten)
+	}
+}
+
+func TestParseMirrorRulesInvalid(t *testing.T) {
+	if _, err := ParseMirrorRules("docker.io/"); err == nil {
+		t.Error("expected a error for a rule without replacement")
+	}
+}
@@ -0,0 +1,75 @@
+package image
+
+import (
+	"sync"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/rs/zerolog/log"
+)
+
+// PullResult holds the outcome of a single image pull
+type PullResult struct {
+	Image    string
+	Duration time.Duration
+	Err      error
+}
+
+// Puller pulls a single image
+type Puller func(image string) error
+
+// RuntimePull pulls the image using the detected container runtime, the output is captured so parallel pulls don't interleave
+func RuntimePull(image string) error {
+	_, err := common.RuntimeOutput("pull", image)
+	return err
+}
+
+// Unique removes duplicate and empty image references while keeping the original order
+func Unique(images []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, image := range images {
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+		result = append(result, image)
+	}
+
+	return result
+}
+
+// PullAll pulls all unique images with at most parallelism concurrent pulls, progress is called once per finished image
+func PullAll(images []string, parallelism int, pull Puller, progress func(PullResult)) []PullResult {
+	images = Unique(images)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]PullResult, len(images))
+	semaphore := make(chan struct{}, parallelism)
+	var progressMutex sync.Mutex
+	var wg sync.WaitGroup
+	for i, image := range images {
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			log.Debug().Str("image", image).Msg("pulling image")
+			start := time.Now()
+			err := pull(image)
+			results[i] = PullResult{Image: image, Duration: time.Since(start), Err: err}
+
+			if progress != nil {
+				progressMutex.Lock()
+				progress(results[i])
+				progressMutex.Unlock()
+			}
+		}(i, image)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,66 @@
+package image
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPullAllDeduplicates(t *testing.T) {
+	var mutex sync.Mutex
+	pulled := make(map[string]int)
+	results := PullAll([]string{"alpine:3", "node:18", "alpine:3", ""}, 4, func(image string) error {
+		mutex.Lock()
+		pulled[image]++
+		mutex.Unlock()
+		return nil
+	}, nil)
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if pulled["alpine:3"] != 1 || pulled["node:18"] != 1 {
+		t.Errorf("expected every image to be pulled exactly once, got %v", pulled)
+	}
+}
+
+func TestPullAllRespectsParallelism(t *testing.T) {
+	var running, maxRunning int32
+	PullAll([]string{"a", "b", "c", "d", "e", "f"}, 2, func(image string) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}, nil)
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent pulls, got %d", maxRunning)
+	}
+}
+
+func TestPullAllReportsFailures(t *testing.T) {
+	var reported int
+	results := PullAll([]string{"ok", "broken"}, 1, func(image string) error {
+		if image == "broken" {
+			return errors.New("manifest unknown")
+		}
+		return nil
+	}, func(result PullResult) {
+		reported++
+	})
+
+	if reported != 2 {
+		t.Errorf("expected progress for 2 images, got %d", reported)
+	}
+	if results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("expected only the second pull to fail, got %v", results)
+	}
+}
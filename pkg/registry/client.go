@@ -0,0 +1,259 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// manifestMediaTypes are the accepted manifest types, indexes first so multi-arch images resolve to the index digest
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Client talks to container registries using the registry http api v2
+type Client struct {
+	// HTTPClient is used for all requests, defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	tokens      map[string]string
+	tokensMutex sync.Mutex
+}
+
+// NewClient creates a new registry client
+func NewClient() *Client {
+	return &Client{HTTPClient: http.DefaultClient}
+}
+
+// Digest resolves the image reference to the digest of its manifest
+func (c *Client) Digest(image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	target := "https://" + ref.apiHost() + "/v2/" + ref.Repository + "/manifests/" + ref.Tag
+	resp, err := c.do(http.MethodHead, target, ref)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve digest of %s: registry responded with %s", image, resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// some registries don't send the digest header on HEAD requests, hash the manifest instead
+	resp, err = c.do(http.MethodGet, target, ref)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch manifest of %s: registry responded with %s", image, resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); !isManifestMediaType(mediaType) {
+		return "", fmt.Errorf("failed to fetch manifest of %s: unexpected content type %q", image, resp.Header.Get("Content-Type"))
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isManifestMediaType checks if the media type is one of the accepted manifest types
+func isManifestMediaType(mediaType string) bool {
+	for _, manifestMediaType := range manifestMediaTypes {
+		if mediaType == manifestMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags lists all tags of the image repository, following the pagination of the registry
+func (c *Client) Tags(image string) ([]string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	target := "https://" + ref.apiHost() + "/v2/" + ref.Repository + "/tags/list"
+	for target != "" {
+		resp, err := c.do(http.MethodGet, target, ref)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list tags of %s: registry responded with %s", ref.Name(), resp.Status)
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+
+		target = nextPage(target, resp.Header.Get("Link"))
+	}
+
+	return tags, nil
+}
+
+// nextPage returns the url of the next page from the link header (<url>; rel="next"), empty if there is no next page
+func nextPage(current string, link string) string {
+	if !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start {
+		return ""
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return ""
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+// do sends the request and transparently handles anonymous bearer token authentication
+func (c *Client) do(method string, target string, ref Reference) (*http.Response, error) {
+	resp, err := c.send(method, target, c.getToken(ref.Name()))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	token, err := c.fetchToken(resp.Header.Get("Www-Authenticate"), ref)
+	if err != nil {
+		return nil, err
+	}
+	c.setToken(ref.Name(), token)
+
+	return c.send(method, target, token)
+}
+
+func (c *Client) send(method string, target string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	log.Trace().Str("method", method).Str("url", target).Msg("registry request")
+	return c.httpClient().Do(req)
+}
+
+// fetchToken requests a anonymous pull token from the realm announced in the authenticate challenge
+func (c *Client) fetchToken(challenge string, ref Reference) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", errors.New("registry " + ref.Registry + " requires unsupported authentication: " + challenge)
+	}
+
+	params := parseChallenge(challenge[len("bearer "):])
+	if params["realm"] == "" {
+		return "", errors.New("registry " + ref.Registry + " sent a authentication challenge without realm")
+	}
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.Repository + ":pull"
+	}
+	query.Set("scope", scope)
+
+	resp, err := c.httpClient().Get(params["realm"] + "?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get token for %s: %s", ref.Name(), resp.Status)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+
+	return tokenResponse.AccessToken, nil
+}
+
+// parseChallenge parses the comma separated key="value" pairs of a authenticate challenge
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(challenge, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) == 2 {
+			params[strings.ToLower(pair[0])] = strings.Trim(pair[1], "\"")
+		}
+	}
+	return params
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *Client) getToken(name string) string {
+	c.tokensMutex.Lock()
+	defer c.tokensMutex.Unlock()
+	return c.tokens[name]
+}
+
+func (c *Client) setToken(name string, token string) {
+	c.tokensMutex.Lock()
+	defer c.tokensMutex.Unlock()
+	if c.tokens == nil {
+		c.tokens = make(map[string]string)
+	}
+	c.tokens[name] = token
+}
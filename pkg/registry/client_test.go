@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testRegistry is a minimal stand-in for a registry that requires anonymous bearer tokens
+type testRegistry struct {
+	server  *httptest.Server
+	digests map[string]string
+	tags    map[string][]string
+	// manifests are served without the digest header, GET requests respond with the manifestStatus if set
+	manifests      map[string]string
+	manifestStatus int
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	registry := &testRegistry{digests: make(map[string]string), tags: make(map[string][]string), manifests: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+registry.server.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		if index := strings.Index(path, "/manifests/"); index != -1 {
+			key := path[:index] + ":" + path[index+len("/manifests/"):]
+			if manifest, ok := registry.manifests[key]; ok {
+				registry.serveManifest(w, r, manifest)
+				return
+			}
+			digest, ok := registry.digests[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+			return
+		}
+		if strings.HasSuffix(path, "/tags/list") {
+			registry.serveTags(w, r, strings.TrimSuffix(path, "/tags/list"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	registry.server = httptest.NewTLSServer(mux)
+	t.Cleanup(registry.server.Close)
+
+	return registry
+}
+
+// serveTags serves the tags of the repository in pages of two tags, like registries that paginate with the link header
+func (r *testRegistry) serveTags(w http.ResponseWriter, req *http.Request, repository string) {
+	tags, ok := r.tags[repository]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	start := 0
+	if last := req.URL.Query().Get("last"); last != "" {
+		for i, tag := range tags {
+			if tag == last {
+				start = i + 1
+			}
+		}
+	}
+	end := start + 2
+	if end < len(tags) {
+		w.Header().Set("Link", `</v2/`+repository+`/tags/list?n=2&last=`+tags[end-1]+`>; rel="next"`)
+	} else {
+		end = len(tags)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags[start:end]})
+}
+
+// serveManifest serves the manifest without the digest header, like registries that only send it on GET requests or not at all
+func (r *testRegistry) serveManifest(w http.ResponseWriter, req *http.Request, manifest string) {
+	if req.Method == http.MethodGet && r.manifestStatus != 0 {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(r.manifestStatus)
+		_, _ = w.Write([]byte("<html>error</html>"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+	if req.Method == http.MethodGet {
+		_, _ = w.Write([]byte(manifest))
+	}
+}
+
+// host returns the registry host that is used in image references
+func (r *testRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "https://")
+}
+
+func TestParseReference(t *testing.T) {
+	cases := map[string]string{
+		"node":                           "docker.io/library/node:latest",
+		"node:18-alpine":                 "docker.io/library/node:18-alpine",
+		"docker.io/envcli/envcli:latest": "docker.io/envcli/envcli:latest",
+		"quay.io/cidverse/build-go:1.20": "quay.io/cidverse/build-go:1.20",
+		"localhost:5000/tools/go":        "localhost:5000/tools/go:latest",
+		"alpine@sha256:abc":              "docker.io/library/alpine@sha256:abc",
+	}
+	for input, expected := range cases {
+		ref, err := ParseReference(input)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", input, err)
+		}
+		if ref.String() != expected {
+			t.Errorf("expected %s to be parsed as %s, got %s", input, expected, ref.String())
+		}
+	}
+}
+
+func TestDigestWithTokenAuthentication(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.digests["cidverse/build-go:1.20"] = "sha256:1111"
+
+	client := &Client{HTTPClient: registry.server.Client()}
+	digest, err := client.Digest(registry.host() + "/cidverse/build-go:1.20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:1111" {
+		t.Errorf("expected digest sha256:1111, got %s", digest)
+	}
+}
+
+func TestDigestUnknownTag(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	client := &Client{HTTPClient: registry.server.Client()}
+	if _, err := client.Digest(registry.host() + "/cidverse/build-go:0.0"); err == nil {
+		t.Error("expected a error for a unknown tag")
+	}
+}
+
+func TestDigestWithoutHeader(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.manifests["cidverse/build-go:1.20"] = `{"schemaVersion":2}`
+
+	client := &Client{HTTPClient: registry.server.Client()}
+	digest, err := client.Digest(registry.host() + "/cidverse/build-go:1.20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum := sha256.Sum256([]byte(`{"schemaVersion":2}`))
+	if digest != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("expected the digest of the manifest, got %s", digest)
+	}
+}
+
+func TestDigestWithoutHeaderErrorResponse(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusOK} {
+		registry := newTestRegistry(t)
+		registry.manifests["cidverse/build-go:1.20"] = `{"schemaVersion":2}`
+		registry.manifestStatus = status
+
+		client := &Client{HTTPClient: registry.server.Client()}
+		if digest, err := client.Digest(registry.host() + "/cidverse/build-go:1.20"); err == nil {
+			t.Errorf("expected a error for the error page with status %d, got digest %s", status, digest)
+		}
+	}
+}
+
+func TestPinDigest(t *testing.T) {
+	if pinned := PinDigest("node:18@sha256:old", "sha256:new"); pinned != "node:18@sha256:new" {
+		t.Errorf("unexpected pinned reference %s", pinned)
+	}
+}
+
+func TestTags(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.tags["library/node"] = []string{"16-alpine", "18", "18-alpine", "20-alpine", "latest"}
+
+	client := &Client{HTTPClient: registry.server.Client()}
+	tags, err := client.Tags(registry.host() + "/library/node:18-alpine")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(tags, ",") != "16-alpine,18,18-alpine,20-alpine,latest" {
+		t.Errorf("expected all pages to be fetched, got %v", tags)
+	}
+
+	if _, err := client.Tags(registry.host() + "/library/unknown:1"); err == nil {
+		t.Error("expected a error for a unknown repository")
+	}
+}
+
+func TestNewerTags(t *testing.T) {
+	cases := map[string]string{
+		"1.20":      "1.22,1.21",
+		"18-alpine": "20-alpine",
+		"v1.9":      "v1.10",
+		"latest":    "",
+	}
+	tags := []string{"1.19", "1.20", "1.21", "1.21.1", "1.22", "1.22-alpine", "16-alpine", "18-alpine", "20-alpine", "v1.9", "v1.10", "latest"}
+
+	for current, expected := range cases {
+		if newer := strings.Join(NewerTags(current, tags), ","); newer != expected {
+			t.Errorf("expected %s for %s, got %s", expected, current, newer)
+		}
+	}
+
+	if image := ReplaceTag("localhost:5000/tools/go:1.20@sha256:abc", "1.21"); image != "localhost:5000/tools/go:1.21" {
+		t.Errorf("unexpected image %s", image)
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultRegistry is the registry used for image references without a registry host
+const DefaultRegistry = "docker.io"
+
+// Reference is a parsed container image reference
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses a image reference like `quay.io/cidverse/build-go:1.20` or `node:18-alpine`
+func ParseReference(image string) (Reference, error) {
+	if image == "" {
+		return Reference{}, errors.New("image reference is empty")
+	}
+	var ref Reference
+	remainder := image
+
+	// digest
+	if index := strings.Index(remainder, "@"); index != -1 {
+		ref.Digest = remainder[index+1:]
+		remainder = remainder[:index]
+	}
+
+	// registry, the first path component is a host if it contains a dot or port or is localhost
+	parts := strings.SplitN(remainder, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		remainder = parts[1]
+	} else {
+		ref.Registry = DefaultRegistry
+	}
+
+	// tag
+	if index := strings.LastIndex(remainder, ":"); index != -1 {
+		ref.Tag = remainder[index+1:]
+		remainder = remainder[:index]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	// official images on docker hub live in the library namespace
+	if ref.Registry == DefaultRegistry && !strings.Contains(remainder, "/") {
+		remainder = "library/" + remainder
+	}
+	ref.Repository = remainder
+	if ref.Repository == "" {
+		return Reference{}, errors.New("image reference " + image + " has no repository")
+	}
+
+	return ref, nil
+}
+
+// Name returns the fully qualified repository name without tag or digest
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the fully qualified image reference
+func (r Reference) String() string {
+	image := r.Name()
+	if r.Tag != "" {
+		image += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		image += "@" + r.Digest
+	}
+	return image
+}
+
+// apiHost returns the host serving the registry api
+func (r Reference) apiHost() string {
+	if r.Registry == DefaultRegistry {
+		return "registry-1.docker.io"
+	}
+	return r.Registry
+}
+
+// PinDigest pins the image reference to the provided digest, a existing digest will be replaced
+func PinDigest(image string, digest string) string {
+	if index := strings.Index(image, "@"); index != -1 {
+		image = image[:index]
+	}
+	return image + "@" + digest
+}
+
+// ReplaceTag replaces the tag of the image reference, a digest will be removed
+func ReplaceTag(image string, tag string) string {
+	if index := strings.Index(image, "@"); index != -1 {
+		image = image[:index]
+	}
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	return image + ":" + tag
+}
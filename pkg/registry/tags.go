@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// numberPattern matches the numeric parts of a tag
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// tagPattern splits the tag into its pattern (the tag with all numbers replaced by a placeholder) and the numbers
+func tagPattern(tag string) (string, []string) {
+	return numberPattern.ReplaceAllString(tag, "#"), numberPattern.FindAllString(tag, -1)
+}
+
+// compareNumbers compares two numbers of arbitrary length
+func compareNumbers(a string, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareTags compares the numbers of two tags with the same pattern
+func compareTags(a []string, b []string) int {
+	for i := range a {
+		if result := compareNumbers(a[i], b[i]); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// NewerTags returns the tags that follow the same pattern as the current tag (ex. 1.20 -> 1.21, 18-alpine -> 20-alpine) and are newer, newest first
+func NewerTags(current string, tags []string) []string {
+	currentPattern, currentNumbers := tagPattern(current)
+	if len(currentNumbers) == 0 {
+		return nil
+	}
+
+	var newer []string
+	numbers := make(map[string][]string)
+	for _, tag := range tags {
+		pattern, tagNumbers := tagPattern(tag)
+		if pattern != currentPattern || compareTags(tagNumbers, currentNumbers) <= 0 {
+			continue
+		}
+		newer = append(newer, tag)
+		numbers[tag] = tagNumbers
+	}
+	sort.SliceStable(newer, func(i, j int) bool {
+		return compareTags(numbers[newer[i]], numbers[newer[j]]) > 0
+	})
+
+	return newer
+}
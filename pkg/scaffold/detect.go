@@ -0,0 +1,175 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/catalog"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Detection is a image entry proposed for a marker file found in the project
+type Detection struct {
+	Marker string
+	Entry  config.RunConfigurationEntry
+}
+
+// detector proposes the catalog tool if one of the marker files exists, markers can be glob patterns
+type detector struct {
+	markers []string
+	tool    string
+	version func(dir string) string
+	adjust  func(dir string, entry *config.RunConfigurationEntry)
+}
+
+var detectors = []detector{
+	{markers: []string{"go.mod"}, tool: "go", version: goVersion},
+	{markers: []string{"package.json"}, tool: "node", version: nodeVersion, adjust: nodePackageManagers},
+	{markers: []string{"pom.xml"}, tool: "maven", version: mavenVersion},
+	{markers: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}, tool: "gradle", version: gradleVersion},
+	{markers: []string{"Cargo.toml"}, tool: "rust", version: rustVersion},
+	{markers: []string{"requirements.txt", "pyproject.toml", "setup.py", "Pipfile"}, tool: "python", version: pythonVersion},
+	{markers: []string{"Gemfile"}, tool: "ruby", version: rubyVersion},
+	{markers: []string{"composer.json"}, tool: "composer"},
+	{markers: []string{"*.tf"}, tool: "terraform", version: terraformVersion},
+	{markers: []string{"Chart.yaml"}, tool: "helm"},
+}
+
+// Detect returns the proposed image entries of the catalog for the marker files in the directory
+func Detect(dir string, toolCatalog catalog.Catalog) []Detection {
+	var detections []Detection
+	for _, d := range detectors {
+		tool, found := toolCatalog.Get(d.tool)
+		if !found {
+			continue
+		}
+
+		for _, marker := range d.markers {
+			matches, _ := filepath.Glob(filepath.Join(dir, marker))
+			if len(matches) == 0 {
+				continue
+			}
+
+			version := ""
+			if d.version != nil {
+				version = d.version(dir)
+			}
+			entry, err := tool.Entry(version)
+			if err != nil {
+				log.Debug().Err(err).Str("tool", d.tool).Msg("detected version is not supported, using the default version")
+				entry, _ = tool.Entry("")
+			}
+			if d.adjust != nil {
+				d.adjust(dir, &entry)
+			}
+			detections = append(detections, Detection{Marker: filepath.Base(matches[0]), Entry: entry})
+			break
+		}
+	}
+
+	return detections
+}
+
+// readFile returns the content of the file in the directory, empty if it can't be read
+func readFile(dir string, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// findVersion returns the first capture group of the pattern in the file, or the default
+func findVersion(dir string, name string, pattern *regexp.Regexp, defaultVersion string) string {
+	if match := pattern.FindStringSubmatch(readFile(dir, name)); match != nil {
+		return match[1]
+	}
+	return defaultVersion
+}
+
+var (
+	goVersionPattern       = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	nodeEnginePattern      = regexp.MustCompile(`"node"\s*:\s*"[^"\d]*(\d+)`)
+	nodeVersionFilePattern = regexp.MustCompile(`^v?(\d+)`)
+	mavenJavaPattern       = regexp.MustCompile(`<(?:maven\.compiler\.release|maven\.compiler\.source|java\.version|release)>(?:1\.)?(\d+)<`)
+	gradleJavaPattern      = regexp.MustCompile(`(?:JavaLanguageVersion\.of\(|JavaVersion\.VERSION_(?:1_)?|sourceCompatibility\s*=\s*['"]?(?:1\.)?)(\d+)`)
+	rustVersionPattern     = regexp.MustCompile(`(?m)^rust-version\s*=\s*"(\d+\.\d+)`)
+	pythonRequiresPattern  = regexp.MustCompile(`requires-python\s*=\s*"[^"\d]*(\d+\.\d+)`)
+	// versionFilePattern matches major.minor of version files like .python-version
+	versionFilePattern      = regexp.MustCompile(`^v?(\d+(?:\.\d+)?)`)
+	terraformVersionPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)$`)
+)
+
+func goVersion(dir string) string {
+	return findVersion(dir, "go.mod", goVersionPattern, "")
+}
+
+// nodeVersion returns the node major version from .nvmrc, .node-version or the engines of the package.json
+func nodeVersion(dir string) string {
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		if match := nodeVersionFilePattern.FindStringSubmatch(strings.TrimSpace(readFile(dir, name))); match != nil {
+			return match[1]
+		}
+	}
+	return findVersion(dir, "package.json", nodeEnginePattern, "")
+}
+
+// nodePackageManagers removes yarn and its cache from the node entry, unless the project uses yarn
+func nodePackageManagers(dir string, entry *config.RunConfigurationEntry) {
+	if _, err := os.Stat(filepath.Join(dir, "yarn.lock")); err == nil {
+		return
+	}
+
+	var provides []string
+	for _, command := range entry.Provides {
+		if command != "yarn" {
+			provides = append(provides, command)
+		}
+	}
+	var caching []config.CachingEntry
+	for _, cache := range entry.Caching {
+		if cache.Name != "yarn" {
+			caching = append(caching, cache)
+		}
+	}
+	entry.Provides, entry.Caching = provides, caching
+}
+
+func mavenVersion(dir string) string {
+	return findVersion(dir, "pom.xml", mavenJavaPattern, "")
+}
+
+func gradleVersion(dir string) string {
+	if version := findVersion(dir, "build.gradle", gradleJavaPattern, ""); version != "" {
+		return version
+	}
+	return findVersion(dir, "build.gradle.kts", gradleJavaPattern, "")
+}
+
+func rustVersion(dir string) string {
+	return findVersion(dir, "Cargo.toml", rustVersionPattern, "")
+}
+
+func pythonVersion(dir string) string {
+	if match := versionFilePattern.FindStringSubmatch(strings.TrimSpace(readFile(dir, ".python-version"))); match != nil {
+		return match[1]
+	}
+	return findVersion(dir, "pyproject.toml", pythonRequiresPattern, "")
+}
+
+func rubyVersion(dir string) string {
+	if match := versionFilePattern.FindStringSubmatch(strings.TrimSpace(readFile(dir, ".ruby-version"))); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func terraformVersion(dir string) string {
+	if match := terraformVersionPattern.FindStringSubmatch(strings.TrimSpace(readFile(dir, ".terraform-version"))); match != nil {
+		return match[1]
+	}
+	return ""
+}
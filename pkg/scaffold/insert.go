@@ -0,0 +1,102 @@
+package scaffold
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	imagesKeyPattern   = regexp.MustCompile(`^images:(\s*\[\s*\])?\s*(#.*)?$`)
+	listItemPattern    = regexp.MustCompile(`^(\s*)- `)
+	topLevelKeyPattern = regexp.MustCompile(`^[^\s#-]`)
+)
+
+// InsertEntry appends the entry to the images of the configuration file content, the existing content including comments is kept as-is
+func InsertEntry(content string, entry config.RunConfigurationEntry) (string, error) {
+	var before config.ConfigurationFile
+	if err := yaml.Unmarshal([]byte(content), &before); err != nil {
+		return "", err
+	}
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// find the images key
+	imagesLine := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "images:") {
+			if !imagesKeyPattern.MatchString(line) {
+				return "", errors.New("images must be a block list to insert a entry")
+			}
+			imagesLine = i
+			break
+		}
+	}
+
+	var result []string
+	if imagesLine == -1 {
+		result = append(lines, "images:")
+		result = append(result, strings.Split(strings.TrimSuffix(RenderEntry(entry, ""), "\n"), "\n")...)
+	} else {
+		// the list ends before the next top-level key, comments directly above that key belong to it
+		end := len(lines)
+		indent := ""
+		indentDetected := false
+		for i := imagesLine + 1; i < len(lines); i++ {
+			if match := listItemPattern.FindStringSubmatch(lines[i]); match != nil && !indentDetected {
+				indent = match[1]
+				indentDetected = true
+			}
+			if topLevelKeyPattern.MatchString(lines[i]) {
+				end = i
+				break
+			}
+		}
+		for end > imagesLine+1 && (strings.TrimSpace(lines[end-1]) == "" || strings.HasPrefix(lines[end-1], "#")) {
+			end--
+		}
+
+		// an empty flow list (images: []) becomes a block list
+		imagesKey := lines[imagesLine]
+		if emptyList := imagesKeyPattern.FindStringSubmatch(imagesKey)[1]; emptyList != "" {
+			imagesKey = strings.Replace(imagesKey, emptyList, "", 1)
+		}
+
+		result = append(result, lines[:imagesLine]...)
+		result = append(result, imagesKey)
+		result = append(result, lines[imagesLine+1:end]...)
+		result = append(result, strings.Split(strings.TrimSuffix(RenderEntry(entry, indent), "\n"), "\n")...)
+		result = append(result, lines[end:]...)
+	}
+	updated := strings.Join(result, newline) + newline
+
+	// verify that the entry was added without changing the existing entries
+	var after config.ConfigurationFile
+	if err := yaml.Unmarshal([]byte(updated), &after); err != nil {
+		return "", errors.New("failed to insert the entry: " + err.Error())
+	}
+	if len(after.Images) != len(before.Images)+1 || after.Images[len(after.Images)-1].Name != entry.Name {
+		return "", errors.New("failed to insert the entry, please add it manually")
+	}
+
+	return updated, nil
+}
+
+// ReplaceImage replaces the image of all entries using the old image, returns the updated content and the number of replaced entries
+func ReplaceImage(content string, oldImage string, newImage string) (string, int) {
+	pattern := regexp.MustCompile(`(?m)^(\s*(?:-\s+)?image:\s*["']?)` + regexp.QuoteMeta(oldImage) + `(["']?\s*(?:#.*)?\r?)$`)
+	replaced := len(pattern.FindAllStringIndex(content, -1))
+
+	return pattern.ReplaceAllString(content, "${1}"+strings.ReplaceAll(newImage, "$", "$$")+"${2}"), replaced
+}
@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// RenderConfiguration renders a .envcli.yml with the entries
+func RenderConfiguration(entries []config.RunConfigurationEntry) string {
+	var content strings.Builder
+	content.WriteString("images:\n")
+	for _, entry := range entries {
+		content.WriteString(RenderEntry(entry, ""))
+	}
+
+	return content.String()
+}
+
+// RenderEntry renders a single entry of the images list, each line is prefixed with the indent
+func RenderEntry(entry config.RunConfigurationEntry, indent string) string {
+	var lines []string
+	lines = append(lines, "- name: "+yamlScalar(entry.Name))
+	if entry.Description != "" {
+		lines = append(lines, "  description: "+yamlScalar(entry.Description))
+	}
+	lines = append(lines, "  provides:")
+	lines = append(lines, yamlList("  ", entry.Provides)...)
+	if entry.Alias != "" {
+		lines = append(lines, "  alias: "+yamlScalar(entry.Alias))
+	}
+	if entry.Image != "" || entry.Build == nil {
+		lines = append(lines, "  image: "+yamlScalar(entry.Image))
+	}
+	if entry.Build != nil {
+		lines = append(lines, "  build:")
+		if entry.Build.Context != "" {
+			lines = append(lines, "    context: "+yamlScalar(entry.Build.Context))
+		}
+		if entry.Build.Dockerfile != "" {
+			lines = append(lines, "    dockerfile: "+yamlScalar(entry.Build.Dockerfile))
+		}
+		if len(entry.Build.Args) > 0 {
+			lines = append(lines, "    args:")
+			for _, name := range sortedKeys(entry.Build.Args) {
+				lines = append(lines, "      "+yamlScalar(name)+": "+yamlScalar(entry.Build.Args[name]))
+			}
+		}
+	}
+	if entry.Directory != "" {
+		lines = append(lines, "  directory: "+yamlScalar(entry.Directory))
+	}
+	if entry.Entrypoint != "" {
+		lines = append(lines, "  entrypoint: "+yamlScalar(entry.Entrypoint))
+	}
+	if entry.Shell != "" {
+		lines = append(lines, "  shell: "+yamlScalar(entry.Shell))
+	}
+	if len(entry.BeforeScript) > 0 {
+		lines = append(lines, "  before_script:")
+		lines = append(lines, yamlList("  ", entry.BeforeScript)...)
+	}
+	if entry.Bake {
+		lines = append(lines, "  bake: true")
+	}
+	if entry.ContainerRuntimeAccess {
+		lines = append(lines, "  containerRuntimeAccess: true")
+	}
+	if len(entry.CapAdd) > 0 {
+		lines = append(lines, "  capAdd:")
+		lines = append(lines, yamlList("  ", entry.CapAdd)...)
+	}
+	if len(entry.Caching) > 0 {
+		lines = append(lines, "  cache:")
+		for _, cache := range entry.Caching {
+			lines = append(lines, "  - name: "+yamlScalar(cache.Name))
+			lines = append(lines, "    directory: "+yamlScalar(cache.ContainerDirectory))
+			if cache.Type != "" {
+				lines = append(lines, "    type: "+yamlScalar(cache.Type))
+			}
+			if cache.Scope != "" {
+				lines = append(lines, "    scope: "+yamlScalar(cache.Scope))
+			}
+			if cache.Lock != "" {
+				lines = append(lines, "    lock: "+yamlScalar(cache.Lock))
+			}
+		}
+	}
+
+	return indent + strings.Join(lines, "\n"+indent) + "\n"
+}
+
+// yamlList renders the values as block sequence items with the indent
+func yamlList(indent string, values []string) []string {
+	var lines []string
+	for _, value := range values {
+		lines = append(lines, indent+"- "+yamlScalar(value))
+	}
+
+	return lines
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// yamlScalar quotes the value if required, long values are quoted instead of folded over multiple lines
+func yamlScalar(value string) string {
+	content, err := yaml.Marshal(value)
+	scalar := strings.TrimSuffix(string(content), "\n")
+	if err != nil || strings.Contains(scalar, "\n") {
+		return strconv.Quote(value)
+	}
+
+	return scalar
+}
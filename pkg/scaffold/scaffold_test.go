@@ -0,0 +1,158 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/catalog"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21.3\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"engines": {"node": ">=18.0.0"}}`), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "yarn.lock"), []byte(""), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<properties><maven.compiler.release>21</maven.compiler.release></properties>"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0644)
+
+	toolCatalog, err := catalog.Load("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	images := make(map[string]config.RunConfigurationEntry)
+	for _, detection := range Detect(dir, toolCatalog) {
+		images[detection.Entry.Name] = detection.Entry
+	}
+
+	expected := map[string]string{
+		"go":        "docker.io/golang:1.21",
+		"node":      "docker.io/node:18",
+		"maven":     "docker.io/maven:3-eclipse-temurin-21",
+		"terraform": "docker.io/hashicorp/terraform:latest",
+	}
+	if len(images) != len(expected) {
+		t.Errorf("expected %d detections, got %v", len(expected), images)
+	}
+	for name, image := range expected {
+		if images[name].Image != image {
+			t.Errorf("expected %s to use %s, got %s", name, image, images[name].Image)
+		}
+	}
+	if len(images["go"].Caching) != 2 {
+		t.Errorf("expected the go caches of the catalog, got %v", images["go"].Caching)
+	}
+	if len(images["node"].Caching) != 2 {
+		t.Errorf("expected the yarn cache to be added, got %v", images["node"].Caching)
+	}
+}
+
+func TestDetectNodeWithoutYarn(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644)
+
+	toolCatalog, err := catalog.Load("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	detections := Detect(dir, toolCatalog)
+	if len(detections) != 1 {
+		t.Fatalf("expected the node detection, got %v", detections)
+	}
+	node := detections[0].Entry
+	if !reflect.DeepEqual(node.Provides, []string{"node", "npm", "npx"}) || len(node.Caching) != 1 || node.Caching[0].Name != "npm" {
+		t.Errorf("expected yarn to be omitted without a yarn.lock, got %v %v", node.Provides, node.Caching)
+	}
+}
+
+func TestRenderConfiguration(t *testing.T) {
+	entries := []config.RunConfigurationEntry{
+		{Name: "go", Description: "Go: a programming language", Provides: []string{"go"}, Image: "docker.io/golang:1.21", Caching: []config.CachingEntry{{Name: "go-build", ContainerDirectory: "/root/.cache/go-build", Scope: "image"}}},
+		{Name: "helm", Description: "Helm is the package manager for Kubernetes, a long description that doesn't fit into a single line.", Provides: []string{"helm"}, Image: "docker.io/alpine/helm:latest"},
+	}
+
+	var cfg config.ConfigurationFile
+	if err := yaml.Unmarshal([]byte(RenderConfiguration(entries)), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Images) != 2 || cfg.Images[0].Description != entries[0].Description || cfg.Images[0].Caching[0].Scope != "image" || cfg.Images[1].Description != entries[1].Description {
+		t.Errorf("unexpected configuration %+v", cfg)
+	}
+}
+
+func TestRenderEntryRoundTrip(t *testing.T) {
+	entry := config.RunConfigurationEntry{
+		Name:                   "tool",
+		Description:            "a tool: with all settings",
+		Provides:               []string{"tool", "tool-helper"},
+		Alias:                  "force",
+		Image:                  "docker.io/alpine:3",
+		Build:                  &config.BuildEntry{Context: "tools", Dockerfile: "tool.Dockerfile", Args: map[string]string{"VERSION": "1.0", "BASE": "alpine:3"}},
+		Directory:              "/src",
+		Entrypoint:             "/bin/sh",
+		Shell:                  "bash",
+		BeforeScript:           []string{"apk add --no-cache git", "echo \"ready\" # done"},
+		Bake:                   true,
+		ContainerRuntimeAccess: true,
+		CapAdd:                 []string{"NET_ADMIN"},
+		Caching:                []config.CachingEntry{{Name: "tool", ContainerDirectory: "/root/.cache", Type: "volume", Scope: "project", Lock: "exclusive"}},
+	}
+
+	var cfg config.ConfigurationFile
+	if err := yaml.UnmarshalStrict([]byte("images:\n"+RenderEntry(entry, "")), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Images) != 1 || !reflect.DeepEqual(cfg.Images[0], entry) {
+		t.Errorf("expected the entry to survive rendering, got %+v", cfg.Images)
+	}
+}
+
+func TestInsertEntry(t *testing.T) {
+	entry := config.RunConfigurationEntry{Name: "helm", Provides: []string{"helm"}, Image: "docker.io/alpine/helm:latest"}
+	cases := map[string]struct{ content, expected string }{
+		"keeps comments and indentation": {
+			content:  "# tools\nimages:\n  # go\n  - name: go\n    provides: [go]\n    image: golang:1.21 # pinned\n\n# trailing\nversion: v1\n",
+			expected: "# tools\nimages:\n  # go\n  - name: go\n    provides: [go]\n    image: golang:1.21 # pinned\n  - name: helm\n    provides:\n    - helm\n    image: docker.io/alpine/helm:latest\n\n# trailing\nversion: v1\n",
+		},
+		"empty list": {
+			content:  "images: []\n",
+			expected: "images:\n- name: helm\n  provides:\n  - helm\n  image: docker.io/alpine/helm:latest\n",
+		},
+		"no images": {
+			content:  "version: v1\n",
+			expected: "version: v1\nimages:\n- name: helm\n  provides:\n  - helm\n  image: docker.io/alpine/helm:latest\n",
+		},
+		"windows line endings": {
+			content:  "images:\r\n- name: go\r\n  image: golang\r\n",
+			expected: "images:\r\n- name: go\r\n  image: golang\r\n- name: helm\r\n  provides:\r\n  - helm\r\n  image: docker.io/alpine/helm:latest\r\n",
+		},
+	}
+
+	for name, c := range cases {
+		updated, err := InsertEntry(c.content, entry)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		} else if updated != c.expected {
+			t.Errorf("%s: unexpected content\n%s", name, updated)
+		}
+	}
+
+	if _, err := InsertEntry("images: [{name: go}]\n", entry); err == nil {
+		t.Error("expected flow lists to be refused")
+	}
+}
+
+func TestReplaceImage(t *testing.T) {
+	content := "images:\n- name: node # lts\n  image: docker.io/node:18-alpine # pinned\n- image: \"docker.io/node:18-alpine\"\n  name: other\n- name: node18\n  image: docker.io/node:18-alpine3.19\n"
+
+	updated, replaced := ReplaceImage(content, "docker.io/node:18-alpine", "docker.io/node:20-alpine")
+	expected := "images:\n- name: node # lts\n  image: docker.io/node:20-alpine # pinned\n- image: \"docker.io/node:20-alpine\"\n  name: other\n- name: node18\n  image: docker.io/node:18-alpine3.19\n"
+	if replaced != 2 || updated != expected {
+		t.Errorf("unexpected content (%d replaced)\n%s", replaced, updated)
+	}
+}
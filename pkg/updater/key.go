@@ -0,0 +1,20 @@
+package updater
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed release-key.pub
+var embeddedReleaseKey string
+
+// ReleasePublicKey returns the embedded public key of the release signing key, lines starting with # are comments
+func ReleasePublicKey() string {
+	for _, line := range strings.Split(embeddedReleaseKey, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+
+	return ""
+}
@@ -1,9 +1,25 @@
 package updater
 
+import "net/http"
+
 /**
  * The Application Update Configuration
  */
 type ApplicationUpdater struct {
-	GitHubOrg         string
-	GitHubRepository  string
+	GitHubOrg        string
+	GitHubRepository string
+	// PublicKey is the base64 encoded DER (PKIX) ECDSA public key the release binaries are signed with, updates are refused if empty
+	PublicKey string
+	// SkipSignature explicitly opts out of the signature verification, only the checksum is verified
+	SkipSignature bool
+	// UpdateURL is a generic http mirror or a GitHub Enterprise API base (ending with /api/v3), github.com is used if empty
+	UpdateURL string
+	// Channel is the release channel (stable or beta), stable is used if empty
+	Channel string
+	// HTTPClient is used for all requests, http.DefaultClient is used if nil
+	HTTPClient *http.Client
+	// StateDirectory keeps the binary replaced by the last update for rollbacks, the binary isn't kept if empty
+	StateDirectory string
+	// TargetPath is the binary that will be replaced, the current executable is used if empty
+	TargetPath string
 }
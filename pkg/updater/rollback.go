@@ -0,0 +1,233 @@
+package updater
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	update "github.com/inconshreveable/go-update"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// stateFileName is the name of the file in the state directory that records the previous version and the update history
+	stateFileName = "update-state.yml"
+	// maxHistoryEntries is the number of updates kept in the history
+	maxHistoryEntries = 20
+)
+
+// UpdateState records the previous binary and the update history
+type UpdateState struct {
+	Previous *SavedVersion  `yaml:"previous,omitempty"`
+	History  []HistoryEntry `yaml:"history,omitempty"`
+}
+
+// SavedVersion is a binary that was replaced by a update
+type SavedVersion struct {
+	Version  string `yaml:"version"`
+	Path     string `yaml:"path"`
+	Checksum string `yaml:"checksum"`
+}
+
+// HistoryEntry is a single update or rollback
+type HistoryEntry struct {
+	From     string    `yaml:"from"`
+	To       string    `yaml:"to"`
+	Date     time.Time `yaml:"date"`
+	Rollback bool      `yaml:"rollback,omitempty"`
+}
+
+// LoadUpdateState reads the update state from the state directory, a missing file results in a empty state
+func LoadUpdateState(stateDirectory string) (UpdateState, error) {
+	var state UpdateState
+
+	content, err := os.ReadFile(filepath.Join(stateDirectory, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return state, err
+	}
+
+	err = yaml.Unmarshal(content, &state)
+	return state, err
+}
+
+// SaveUpdateState writes the update state into the state directory
+func SaveUpdateState(stateDirectory string, state UpdateState) error {
+	content, err := yaml.Marshal(&state)
+	if err != nil {
+		return err
+	}
+
+	return common.WriteFileAtomic(filepath.Join(stateDirectory, stateFileName), content, 0644)
+}
+
+// packageManagerPaths are path fragments of binaries installed by package managers
+var packageManagerPaths = map[string]string{
+	"/usr/bin/":                   "the system package manager",
+	"/usr/sbin/":                  "the system package manager",
+	"/usr/lib/":                   "the system package manager",
+	"/opt/homebrew/":              "homebrew",
+	"/usr/local/cellar/":          "homebrew",
+	"/home/linuxbrew/.linuxbrew/": "homebrew",
+	"/nix/store/":                 "nix",
+	"/snap/":                      "snap",
+	"/scoop/apps/":                "scoop",
+	"/chocolatey/":                "chocolatey",
+	"/winget/packages/":           "winget",
+}
+
+// PackageManager returns the package manager that installed the binary, binaries managed by a package manager shouldn't be replaced
+func PackageManager(binary string) (string, bool) {
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	normalized := strings.ToLower(filepath.ToSlash(binary))
+
+	for fragment, manager := range packageManagerPaths {
+		if strings.Contains(normalized, fragment) {
+			return manager, true
+		}
+	}
+
+	return "", false
+}
+
+// targetPath returns the binary that will be replaced
+func (appUpdater ApplicationUpdater) targetPath() (string, error) {
+	if appUpdater.TargetPath != "" {
+		return appUpdater.TargetPath, nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// previousBinaryPath returns the location the replaced binary is kept at
+func (appUpdater ApplicationUpdater) previousBinaryPath() string {
+	name := "envcli-previous"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return filepath.Join(appUpdater.StateDirectory, name)
+}
+
+// copyBinary copies the binary to a temporary file next to the destination, returns the temporary file and the sha256 checksum
+func copyBinary(source string, destination string) (string, string, error) {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return "", "", err
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return "", "", err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(destination), "."+filepath.Base(destination)+".*.tmp")
+	if err != nil {
+		return "", "", err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hash), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(out.Name(), 0755)
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", "", err
+	}
+
+	return out.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// keepPreviousVersion copies the current binary into the state directory and records it, once replace succeeded
+func (appUpdater ApplicationUpdater) keepPreviousVersion(currentVersion string, targetVersion string, rollback bool, replace func(target string) error) error {
+	target, err := appUpdater.targetPath()
+	if err != nil {
+		return err
+	}
+	if appUpdater.StateDirectory == "" {
+		return replace(target)
+	}
+
+	state, err := LoadUpdateState(appUpdater.StateDirectory)
+	if err != nil {
+		log.Warn().Err(err).Msg("update state is corrupt, recreating it")
+	}
+
+	// the copy only replaces the previous binary after the update succeeded
+	previousPath := appUpdater.previousBinaryPath()
+	tempFile, checksum, err := copyBinary(target, previousPath)
+	if err != nil {
+		return fmt.Errorf("failed to keep the current version for rollbacks: %w", err)
+	}
+	defer os.Remove(tempFile)
+
+	if err := replace(target); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempFile, previousPath); err != nil {
+		return fmt.Errorf("failed to keep the current version for rollbacks: %w", err)
+	}
+	state.Previous = &SavedVersion{Version: currentVersion, Path: previousPath, Checksum: checksum}
+	state.History = append(state.History, HistoryEntry{From: currentVersion, To: targetVersion, Date: time.Now().UTC(), Rollback: rollback})
+	if len(state.History) > maxHistoryEntries {
+		state.History = state.History[len(state.History)-maxHistoryEntries:]
+	}
+
+	return SaveUpdateState(appUpdater.StateDirectory, state)
+}
+
+// Rollback restores the binary that was replaced by the last update, the replaced binary is kept so the rollback can be undone
+func (appUpdater ApplicationUpdater) Rollback(appVersion string) (string, error) {
+	if appUpdater.StateDirectory == "" {
+		return "", errors.New("no state directory configured")
+	}
+	state, err := LoadUpdateState(appUpdater.StateDirectory)
+	if err != nil {
+		return "", err
+	}
+	if state.Previous == nil {
+		return "", errors.New("no previous version available, rollbacks are only possible after a update")
+	}
+	previous := *state.Previous
+
+	checksum, err := hex.DecodeString(previous.Checksum)
+	if err != nil {
+		return "", fmt.Errorf("invalid checksum of the previous version: %w", err)
+	}
+	binary, err := os.ReadFile(previous.Path)
+	if err != nil {
+		return "", fmt.Errorf("previous version is missing: %w", err)
+	}
+
+	err = appUpdater.keepPreviousVersion(appVersion, previous.Version, true, func(target string) error {
+		return applyUpdate(bytes.NewReader(binary), update.Options{TargetPath: target, Checksum: checksum, Hash: crypto.SHA256})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Info().Msg("Successfully rolled back from [" + appVersion + "] to [" + previous.Version + "]!")
+	return previous.Version, nil
+}
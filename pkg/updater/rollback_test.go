@@ -0,0 +1,67 @@
+package updater
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+mirrorIndexFileName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"releases":[{"version":"v1.1.0","assets":["` + assetName() + `"]}]}`))
+	})
+	serveRelease(t, mux, "/v1.1.0", []byte("new binary"), key)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	target := filepath.Join(t.TempDir(), "envcli")
+	_ = os.WriteFile(target, []byte("old binary"), 0755)
+	appUpdater := ApplicationUpdater{PublicKey: publicKey(t, key), UpdateURL: server.URL, HTTPClient: server.Client(), TargetPath: target, StateDirectory: t.TempDir()}
+
+	if _, err := appUpdater.Rollback("v1.0.0"); err == nil {
+		t.Error("expected a rollback without a previous update to fail")
+	}
+	if err := appUpdater.Update("latest", false, "v1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// rollback to the replaced version
+	version, err := appUpdater.Rollback("v1.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(target); version != "v1.0.0" || string(content) != "old binary" {
+		t.Errorf("expected the old binary to be restored, got %s %q", version, content)
+	}
+
+	// the rollback itself can be undone
+	state, _ := LoadUpdateState(appUpdater.StateDirectory)
+	if state.Previous == nil || state.Previous.Version != "v1.1.0" || len(state.History) != 2 || !state.History[1].Rollback {
+		t.Errorf("unexpected update state %+v", state)
+	}
+
+	// a tampered previous binary is refused
+	_ = os.WriteFile(state.Previous.Path, []byte("tampered binary"), 0755)
+	if _, err := appUpdater.Rollback("v1.0.0"); err == nil {
+		t.Error("expected a tampered binary to be refused")
+	}
+	if content, _ := os.ReadFile(target); string(content) != "old binary" {
+		t.Errorf("expected the binary to be unchanged, got %q", content)
+	}
+}
+
+func TestPackageManager(t *testing.T) {
+	if manager, managed := PackageManager("/opt/homebrew/Cellar/envcli/1.0.0/bin/envcli"); !managed || manager != "homebrew" {
+		t.Errorf("expected homebrew, got %q", manager)
+	}
+	if _, managed := PackageManager("/home/user/.local/bin/envcli"); managed {
+		t.Error("expected a binary in a user directory to be unmanaged")
+	}
+}
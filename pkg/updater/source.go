@@ -0,0 +1,153 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	github "github.com/google/go-github/v26/github"
+	"github.com/rs/zerolog/log"
+)
+
+// mirrorIndexFileName is the name of the version index served by update mirrors
+const mirrorIndexFileName = "index.json"
+
+// Source provides the available versions and the download location of the release assets
+type Source interface {
+	// Releases returns all published releases
+	Releases(ctx context.Context) ([]Release, error)
+	// ReleaseURL returns the url the assets of the version can be downloaded from
+	ReleaseURL(version string) string
+}
+
+// Release is a published version and the names of its assets
+type Release struct {
+	Version    string   `json:"version"`
+	Prerelease bool     `json:"prerelease,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	Assets     []string `json:"assets"`
+}
+
+// MirrorIndex is the version index of a update mirror, the assets of a version are served below <update-url>/<version>/
+type MirrorIndex struct {
+	Releases []Release `json:"releases"`
+}
+
+// githubSource discovers versions using the GitHub (Enterprise) API
+type githubSource struct {
+	client      *github.Client
+	org         string
+	repository  string
+	downloadURL string
+}
+
+// Releases returns the published releases of the repository, drafts are ignored
+func (s githubSource) Releases(ctx context.Context) ([]Release, error) {
+	var releases []Release
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := s.client.Repositories.ListReleases(ctx, s.org, s.repository, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, release := range page {
+			if release.GetDraft() {
+				continue
+			}
+			log.Debug().Msg("Found Release in Source Repository: " + release.GetTagName())
+
+			var assets []string
+			for _, asset := range release.Assets {
+				assets = append(assets, asset.GetName())
+			}
+			releases = append(releases, Release{Version: release.GetTagName(), Prerelease: release.GetPrerelease(), Notes: release.GetBody(), Assets: assets})
+		}
+
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// ReleaseURL returns the download url of the release
+func (s githubSource) ReleaseURL(version string) string {
+	return fmt.Sprintf("%s/%s/%s/releases/download/%s", s.downloadURL, s.org, s.repository, version)
+}
+
+// mirrorSource discovers versions using the index of a generic http mirror
+type mirrorSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// Releases returns the releases of the mirror index
+func (s mirrorSource) Releases(ctx context.Context) ([]Release, error) {
+	index, err := s.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Releases, nil
+}
+
+// ReleaseURL returns the directory of the version on the mirror
+func (s mirrorSource) ReleaseURL(version string) string {
+	return s.baseURL + "/" + version
+}
+
+// index fetches the version index of the mirror
+func (s mirrorSource) index(ctx context.Context) (MirrorIndex, error) {
+	var index MirrorIndex
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/"+mirrorIndexFileName, nil)
+	if err != nil {
+		return index, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return index, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return index, fmt.Errorf("failed to fetch %s from update mirror, status %s", mirrorIndexFileName, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&index)
+	return index, err
+}
+
+// isGitHubAPI checks if the update url is the base url of a GitHub (Enterprise) API
+func isGitHubAPI(updateURL string) bool {
+	return updateURL == "https://api.github.com" || strings.HasSuffix(updateURL, "/api/v3")
+}
+
+// source returns the update source, github.com by default or the configured mirror / GitHub Enterprise API
+func (appUpdater ApplicationUpdater) source() (Source, error) {
+	updateURL := strings.TrimSuffix(appUpdater.UpdateURL, "/")
+	if updateURL == "" || updateURL == "https://api.github.com" {
+		return githubSource{client: github.NewClient(appUpdater.httpClient()), org: appUpdater.GitHubOrg, repository: appUpdater.GitHubRepository, downloadURL: "https://github.com"}, nil
+	}
+
+	if isGitHubAPI(updateURL) {
+		client, err := github.NewEnterpriseClient(updateURL, updateURL, appUpdater.httpClient())
+		if err != nil {
+			return nil, err
+		}
+		return githubSource{client: client, org: appUpdater.GitHubOrg, repository: appUpdater.GitHubRepository, downloadURL: strings.TrimSuffix(updateURL, "/api/v3")}, nil
+	}
+
+	return mirrorSource{client: appUpdater.httpClient(), baseURL: updateURL}, nil
+}
+
+// httpClient returns the configured http client or the default client
+func (appUpdater ApplicationUpdater) httpClient() *http.Client {
+	if appUpdater.HTTPClient != nil {
+		return appUpdater.HTTPClient
+	}
+
+	return http.DefaultClient
+}
@@ -0,0 +1,97 @@
+package updater
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateFromMirror(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+mirrorIndexFileName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.ReplaceAll(`{"releases":[{"version":"v1.0.0","assets":["ASSET"]},{"version":"v1.1.0","assets":["ASSET"]},{"version":"invalid","assets":["ASSET"]}]}`, "ASSET", assetName())))
+	})
+	serveRelease(t, mux, "/v1.1.0", []byte("mirrored binary"), key)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	target := filepath.Join(t.TempDir(), "envcli")
+	_ = os.WriteFile(target, []byte("old binary"), 0755)
+
+	appUpdater := ApplicationUpdater{PublicKey: publicKey(t, key), UpdateURL: server.URL + "/", HTTPClient: server.Client(), TargetPath: target}
+	if !appUpdater.IsUpdateAvailable("1.0.0") {
+		t.Error("expected v1.1.0 to be available")
+	}
+	if err := appUpdater.Update("latest", false, "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "mirrored binary" {
+		t.Errorf("expected the binary to be replaced, got %q", content)
+	}
+}
+
+func TestUpdateFromGitHubEnterprise(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/EnvCLI/EnvCLI/releases", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.ReplaceAll(`[{"tag_name":"v1.3.0","draft":true,"assets":[{"name":"ASSET"}]},{"tag_name":"v1.2.0","assets":[{"name":"ASSET"}]},{"tag_name":"v0.9.0","assets":[{"name":"ASSET"}]}]`, "ASSET", assetName())))
+	})
+	serveRelease(t, mux, "/EnvCLI/EnvCLI/releases/download/v1.2.0", []byte("enterprise binary"), key)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	target := filepath.Join(t.TempDir(), "envcli")
+	_ = os.WriteFile(target, []byte("old binary"), 0755)
+
+	appUpdater := ApplicationUpdater{GitHubOrg: "EnvCLI", GitHubRepository: "EnvCLI", PublicKey: publicKey(t, key), UpdateURL: server.URL + "/api/v3", HTTPClient: server.Client(), TargetPath: target}
+	if err := appUpdater.Update("latest", false, "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "enterprise binary" {
+		t.Errorf("expected the binary to be replaced, got %q", content)
+	}
+}
+
+func TestAvailableReleases(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+mirrorIndexFileName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.ReplaceAll(`{"releases":[
+			{"version":"v1.0.0","assets":["ASSET"]},
+			{"version":"v1.2.0","assets":["ASSET"]},
+			{"version":"v2.0.0-rc.1","assets":["ASSET"]},
+			{"version":"v1.3.0","prerelease":true,"assets":["ASSET"]},
+			{"version":"v1.4.0","assets":["other_arch"]}
+		]}`, "ASSET", assetName())))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := map[string]string{
+		ChannelStable: "v1.2.0 v1.0.0",
+		ChannelBeta:   "v2.0.0-rc.1 v1.3.0 v1.2.0 v1.0.0",
+	}
+	for channel, expected := range cases {
+		releases, err := ApplicationUpdater{UpdateURL: server.URL, HTTPClient: server.Client(), Channel: channel}.AvailableReleases()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var versions []string
+		for _, release := range releases {
+			versions = append(versions, release.Version)
+		}
+		if strings.Join(versions, " ") != expected {
+			t.Errorf("channel %s: expected %s, got %v", channel, expected, versions)
+		}
+	}
+
+	if _, err := (ApplicationUpdater{UpdateURL: server.URL, Channel: "nightly"}).AvailableReleases(); err == nil {
+		t.Error("expected a invalid channel to be refused")
+	}
+}
@@ -2,129 +2,263 @@ package updater
 
 import (
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"io"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/blang/semver"
-	github "github.com/google/go-github/v26/github"
+	"github.com/cidverse/cidverseutils/pkg/collection"
 	update "github.com/inconshreveable/go-update"
+	"github.com/rs/zerolog/log"
 )
 
-// Find the latest version of the applicaton
-func (appUpdater ApplicationUpdater) getLatestVersion() string {
-	// Get Latest Version from Link
-	var version = ""
-	ctx := context.Background()
-	client := github.NewClient(nil)
-	opt := &github.ListOptions{Page: 0, PerPage: 500}
-	tags, _, err := client.Repositories.ListTags(ctx, appUpdater.GitHubOrg, appUpdater.GitHubRepository, opt)
-	if err != nil {
-		log.Error().Err(err).Msg("Unexpected GitHub Error")
-		return ""
+// ChannelStable only considers stable releases, ChannelBeta also considers pre-releases
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// AvailableReleases returns the releases of the channel that provide a binary for the current platform, newest first
+func (appUpdater ApplicationUpdater) AvailableReleases() ([]Release, error) {
+	channel := appUpdater.Channel
+	if channel == "" {
+		channel = ChannelStable
+	}
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, errors.New("invalid update channel [" + channel + "], allowed: " + ChannelStable + ", " + ChannelBeta)
 	}
 
-	// Find newest tag
-	currentVersion, _ := semver.Make("0.0.0")
-	for _, tag := range tags {
-		log.Debug().Msg("Found Tag in Source Repository: "+*tag.Name+" ["+*tag.Commit.SHA+"]")
+	source, err := appUpdater.source()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := source.Releases(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
-		tagVersion, err := semver.Make(strings.TrimLeft(*tag.Name, "v"))
+	var available []Release
+	versions := make(map[string]semver.Version)
+	for _, release := range releases {
+		releaseVersion, err := semver.Make(strings.TrimLeft(release.Version, "v"))
 		if err != nil {
-			log.Debug().Err(err).Msg("Unexpected error parsing the github tag: " + err.Error())
+			log.Debug().Err(err).Msg("Unexpected error parsing the release version: " + release.Version)
 			continue
 		}
-		// GTE: sourceVersion greater than or equal to targetVersion
-		if tagVersion.GTE(currentVersion) {
-			currentVersion = tagVersion
-			version = fmt.Sprintf("v%s", tagVersion.String())
+		if channel == ChannelStable && (release.Prerelease || len(releaseVersion.Pre) > 0) {
+			continue
+		}
+		if hasAsset, _ := collection.InArray(assetName(), release.Assets); !hasAsset {
+			log.Debug().Msg("Release " + release.Version + " has no binary for " + assetName() + ", skipping")
+			continue
 		}
+
+		available = append(available, release)
+		versions[release.Version] = releaseVersion
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		return versions[available[i].Version].GT(versions[available[j].Version])
+	})
+
+	return available, nil
+}
+
+// LatestVersion returns the latest version of the update channel
+func (appUpdater ApplicationUpdater) LatestVersion() (string, error) {
+	releases, err := appUpdater.AvailableReleases()
+	if err != nil {
+		return "", err
+	}
+	if len(releases) == 0 {
+		return "", errors.New("no release available for " + assetName())
 	}
 
-	log.Debug().Msg("Latest version is "+version+".")
-	return version
+	log.Debug().Msg("Latest version is " + releases[0].Version + ".")
+	return releases[0].Version, nil
 }
 
-// applyUpdate ...
-func applyUpdate(resp *http.Response) {
-	opts := update.Options{}
-	err := opts.CheckPermissions()
+// checksumFileName is the name of the release asset that contains the sha256 checksums of all binaries
+const checksumFileName = "checksums.txt"
+
+// assetName returns the name of the release binary for the current platform
+func assetName() string {
+	return fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// download fetches the url and returns the response body, non-200 responses are errors
+func (appUpdater ApplicationUpdater) download(url string) (io.ReadCloser, error) {
+	log.Debug().Msg("Starting download from remote: " + url)
+	resp, err := appUpdater.httpClient().Get(url)
 	if err != nil {
-		log.Error().Err(err).Msg("Missing permissions, update can't be executed: "+err.Error())
-		return
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download of %s failed with status %s", url, resp.Status)
 	}
-	err = update.Apply(resp.Body, opts)
+
+	return resp.Body, nil
+}
+
+// downloadBytes fetches the url and returns the content
+func (appUpdater ApplicationUpdater) downloadBytes(url string) ([]byte, error) {
+	body, err := appUpdater.download(url)
 	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// parseChecksums parses a checksum manifest in the sha256sum format (<hex>  <file>)
+func parseChecksums(content string) map[string]string {
+	checksums := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+
+	return checksums
+}
+
+// updateOptions returns the update options that verify the checksum and the signature of the release binary, the signature is only skipped on explicit opt-out
+func (appUpdater ApplicationUpdater) updateOptions(releaseURL string, asset string) (update.Options, error) {
+	opts := update.Options{Hash: crypto.SHA256, TargetPath: appUpdater.TargetPath}
+
+	// checksum
+	checksumContent, err := appUpdater.downloadBytes(releaseURL + "/" + checksumFileName)
+	if err != nil {
+		return opts, fmt.Errorf("failed to download checksum manifest: %w", err)
+	}
+	checksum, ok := parseChecksums(string(checksumContent))[asset]
+	if !ok {
+		return opts, errors.New("no checksum for " + asset + " in " + checksumFileName)
+	}
+	if opts.Checksum, err = hex.DecodeString(checksum); err != nil || len(opts.Checksum) != sha256.Size {
+		return opts, errors.New("invalid checksum for " + asset + " in " + checksumFileName)
+	}
+
+	// signature
+	if appUpdater.SkipSignature {
+		log.Warn().Msg("Signature verification is disabled, only the checksum of the update is verified.")
+		return opts, nil
+	}
+	if appUpdater.PublicKey == "" {
+		return opts, errors.New("this build has no release public key, the signature of the update can't be verified")
+	}
+	keyBytes, err := base64.StdEncoding.DecodeString(appUpdater.PublicKey)
+	if err != nil {
+		return opts, fmt.Errorf("invalid public key: %w", err)
+	}
+	if opts.PublicKey, err = x509.ParsePKIXPublicKey(keyBytes); err != nil {
+		return opts, fmt.Errorf("invalid public key: %w", err)
+	}
+	if opts.Signature, err = appUpdater.downloadBytes(releaseURL + "/" + asset + ".sig"); err != nil {
+		return opts, fmt.Errorf("failed to download signature: %w", err)
+	}
+	opts.Verifier = update.NewECDSAVerifier()
+
+	return opts, nil
+}
+
+// applyUpdate replaces the current executable, the update is only applied if the checksum and signature match
+func applyUpdate(binary io.Reader, opts update.Options) error {
+	if err := opts.CheckPermissions(); err != nil {
+		return fmt.Errorf("missing permissions, update can't be executed: %w", err)
+	}
+
+	if err := update.Apply(binary, opts); err != nil {
 		if rerr := update.RollbackError(err); rerr != nil {
-			log.Error().Err(err).Msg("Broken update, failed to rollback. Please reinstall the application.")
-		} else {
-			log.Error().Err(err).Msg("Broken update detected, aborted.")
+			return fmt.Errorf("broken update, failed to rollback, please reinstall the application: %w", rerr)
 		}
-		return
+		return fmt.Errorf("broken update detected, aborted: %w", err)
 	}
+
+	return nil
 }
 
-// newVersionDownloader ...
-func (appUpdater ApplicationUpdater) newVersionDownloader(version string) {
-	var downloadURL = fmt.Sprintf("https://github.com/EnvCLI/EnvCLI/releases/download/%s/%s_%s", version, runtime.GOOS, runtime.GOARCH)
-	log.Debug().Msg("Starting download from remote: " + downloadURL)
+// newVersionDownloader downloads and verifies the binary of the version and replaces the current executable
+func (appUpdater ApplicationUpdater) newVersionDownloader(version string, appVersion string) error {
+	source, err := appUpdater.source()
+	if err != nil {
+		return err
+	}
+	releaseURL := source.ReleaseURL(version)
+	asset := assetName()
 
-	// download new version
-	resp, err := http.Get(downloadURL)
+	opts, err := appUpdater.updateOptions(releaseURL, asset)
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return err
 	}
-	if resp.StatusCode != 200 {
-		log.Error().Msg("Update not found on remote server ... aborting.")
-		return
+
+	binary, err := appUpdater.download(releaseURL + "/" + asset)
+	if err != nil {
+		return err
 	}
+	defer binary.Close()
 
-	defer resp.Body.Close()
-	applyUpdate(resp)
+	return appUpdater.keepPreviousVersion(appVersion, version, false, func(target string) error {
+		opts.TargetPath = target
+		return applyUpdate(binary, opts)
+	})
 }
 
-// Update interface
-func (appUpdater ApplicationUpdater) Update(version string, force bool, appVersion string) {
+// Update updates the application to the version, the update is refused if the download can't be verified
+func (appUpdater ApplicationUpdater) Update(version string, force bool, appVersion string) error {
 	// current application version
 	applicationVersion, err := semver.Make(strings.TrimLeft(appVersion, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return err
 	}
 
 	// set to latest version of no version is specified
 	if version == "latest" {
-		version = appUpdater.getLatestVersion()
+		if version, err = appUpdater.LatestVersion(); err != nil {
+			return fmt.Errorf("failed to determine the latest version: %w", err)
+		}
 	}
 
 	// update target version
 	updateTargetVersion, err := semver.Make(strings.TrimLeft(version, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return err
 	}
 
 	if applicationVersion.Compare(updateTargetVersion) == 0 && force == false {
 		log.Info().Msg("No update available, already at the latest version!")
-		return
+		return nil
 	}
 
 	if force == true {
 		log.Debug().Msg("Initiating forced update to version: " + updateTargetVersion.String())
 	}
-	appUpdater.newVersionDownloader(version)
+	if err := appUpdater.newVersionDownloader(version, appVersion); err != nil {
+		return err
+	}
 
 	// Log Result
 	if applicationVersion.GT(updateTargetVersion) {
-		log.Info().Msg("Successfully downgraded from ["+applicationVersion.String()+"] to ["+updateTargetVersion.String()+"]!")
+		log.Info().Msg("Successfully downgraded from [" + applicationVersion.String() + "] to [" + updateTargetVersion.String() + "]!")
 	} else if applicationVersion.LT(updateTargetVersion) {
-		log.Info().Msg("Successfully upgraded from ["+applicationVersion.String()+"] to ["+updateTargetVersion.String()+"]!")
+		log.Info().Msg("Successfully upgraded from [" + applicationVersion.String() + "] to [" + updateTargetVersion.String() + "]!")
 	} else {
-		log.Info().Msg("Successfully downloaded ["+applicationVersion.String()+"]!")
+		log.Info().Msg("Successfully downloaded [" + applicationVersion.String() + "]!")
 	}
+
+	return nil
 }
 
 // Update interface
@@ -132,16 +266,20 @@ func (appUpdater ApplicationUpdater) IsUpdateAvailable(appVersion string) bool {
 	// current application version
 	applicationVersion, err := semver.Make(strings.TrimLeft(appVersion, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
+		log.Error().Err(err).Msg("Unexpected Error: " + err.Error())
 		return false
 	}
 
-	var version = appUpdater.getLatestVersion()
+	version, err := appUpdater.LatestVersion()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch the available versions")
+		return false
+	}
 
 	// update target version
 	updateTargetVersion, err := semver.Make(strings.TrimLeft(version, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
+		log.Error().Err(err).Msg("Unexpected Error: " + err.Error())
 		return false
 	}
 
@@ -152,3 +290,17 @@ func (appUpdater ApplicationUpdater) IsUpdateAvailable(appVersion string) bool {
 
 	return false
 }
+
+// IsNewerVersion checks if the version is newer than the current version, invalid versions are never newer
+func IsNewerVersion(version string, currentVersion string) bool {
+	parsedVersion, err := semver.Make(strings.TrimLeft(version, "v"))
+	if err != nil {
+		return false
+	}
+	parsedCurrentVersion, err := semver.Make(strings.TrimLeft(currentVersion, "v"))
+	if err != nil {
+		return false
+	}
+
+	return parsedVersion.GT(parsedCurrentVersion)
+}
@@ -0,0 +1,148 @@
+package updater
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testRelease serves the binary, checksum manifest and signature of a release
+func testRelease(t *testing.T, binary []byte, key *ecdsa.PrivateKey) *httptest.Server {
+	mux := http.NewServeMux()
+	serveRelease(t, mux, "", binary, key)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// serveRelease registers the release assets below the prefix
+func serveRelease(t *testing.T, mux *http.ServeMux, prefix string, binary []byte, key *ecdsa.PrivateKey) {
+	checksum := sha256.Sum256(binary)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, checksum[:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mux.HandleFunc(prefix+"/"+assetName(), func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc(prefix+"/"+assetName()+".sig", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(signature) })
+	mux.HandleFunc(prefix+"/"+checksumFileName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(hex.EncodeToString(checksum[:]) + "  " + assetName() + "\n0000  other_arch\n"))
+	})
+}
+
+func publicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksums := parseChecksums("ABC123  linux_amd64\n\ndef456 *windows_amd64\ninvalid\n")
+
+	if checksums["linux_amd64"] != "abc123" || checksums["windows_amd64"] != "def456" || len(checksums) != 2 {
+		t.Errorf("unexpected checksums %v", checksums)
+	}
+}
+
+func TestApplyVerifiedUpdate(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server := testRelease(t, []byte("new binary"), key)
+	target := filepath.Join(t.TempDir(), "envcli")
+	_ = os.WriteFile(target, []byte("old binary"), 0755)
+
+	appUpdater := ApplicationUpdater{PublicKey: publicKey(t, key)}
+	opts, err := appUpdater.updateOptions(server.URL, assetName())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts.TargetPath = target
+
+	if err := applyUpdate(bytes.NewReader([]byte("new binary")), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "new binary" {
+		t.Errorf("expected the binary to be replaced, got %q", content)
+	}
+}
+
+func TestRefuseUnverifiedUpdate(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server := testRelease(t, []byte("new binary"), key)
+	target := filepath.Join(t.TempDir(), "envcli")
+	_ = os.WriteFile(target, []byte("old binary"), 0755)
+
+	// tampered binary, also without signature verification
+	opts, _ := ApplicationUpdater{SkipSignature: true}.updateOptions(server.URL, assetName())
+	opts.TargetPath = target
+	if err := applyUpdate(bytes.NewReader([]byte("tampered binary")), opts); err == nil {
+		t.Error("expected a checksum mismatch to be refused")
+	}
+
+	// build without public key
+	if _, err := (ApplicationUpdater{}).updateOptions(server.URL, assetName()); err == nil {
+		t.Error("expected a update without public key to be refused")
+	}
+
+	// signature of a different key
+	opts, _ = ApplicationUpdater{PublicKey: publicKey(t, otherKey)}.updateOptions(server.URL, assetName())
+	opts.TargetPath = target
+	if err := applyUpdate(bytes.NewReader([]byte("new binary")), opts); err == nil {
+		t.Error("expected a invalid signature to be refused")
+	}
+
+	if content, _ := os.ReadFile(target); string(content) != "old binary" {
+		t.Errorf("expected the binary to be unchanged, got %q", content)
+	}
+}
+
+func TestReleasePublicKey(t *testing.T) {
+	original := embeddedReleaseKey
+	t.Cleanup(func() { embeddedReleaseKey = original })
+
+	embeddedReleaseKey = "# comment\n\n  MFkwEwYHKoZIzj0CAQ==  \n"
+	if key := ReleasePublicKey(); key != "MFkwEwYHKoZIzj0CAQ==" {
+		t.Errorf("unexpected key %q", key)
+	}
+	embeddedReleaseKey = "# comment only\n"
+	if key := ReleasePublicKey(); key != "" {
+		t.Errorf("expected no key, got %q", key)
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	cases := []struct {
+		version  string
+		current  string
+		expected bool
+	}{
+		{"1.2.0", "1.1.0", true},
+		{"v1.2.0", "1.1.9", true},
+		{"1.10.0", "1.9.0", true},
+		{"1.0.0", "1.0.0-rc.1", true},
+		{"1.1.0", "1.1.0", false},
+		{"1.0.0", "1.1.0", false},
+		{"", "1.0.0", false},
+		{"1.0.0", "dev", false},
+		{"latest", "1.0.0", false},
+	}
+
+	for _, c := range cases {
+		if IsNewerVersion(c.version, c.current) != c.expected {
+			t.Errorf("expected IsNewerVersion(%q, %q) to be %v", c.version, c.current, c.expected)
+		}
+	}
+}
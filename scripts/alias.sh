@@ -18,7 +18,7 @@ done
 # call envcli for the alias and pass all arguments
 ENVCLI_DEBUG=${ENVCLI_DEBUG:-false}
 if [ "$ENVCLI_DEBUG" == "true" ]; then
-    eval envcli --loglevel=debug run $aliasFor $allargs
+    eval envcli --log-level=debug run $aliasFor $allargs
 else
     eval envcli run $aliasFor $allargs
 fi